@@ -0,0 +1,104 @@
+package streamdeck
+
+import (
+	"sync"
+
+	"github.com/FlowingSPDG/streamdeck/manifest"
+	"golang.org/x/xerrors"
+)
+
+// ActionSpec Declaration of an action. Register it with Client.RegisterAction, and generate the manifest from the code with Client.GenerateManifest.
+type ActionSpec struct {
+	UUID string
+	Name string
+	// Icon image of the action in the actions list, without extension.
+	Icon    string
+	Tooltip string
+	// States states of the action, 1 or 2. A single state with Icon as image is used when empty.
+	States []manifest.State
+	// Controllers controllers the action supports. Keypad when empty.
+	Controllers []manifest.Controller
+	// Encoder display of the action on a Stream Deck +, for the Encoder controller.
+	Encoder                 *manifest.ActionEncoder
+	PropertyInspectorPath   string
+	SupportedInMultiActions bool
+}
+
+type actionSpecs struct {
+	mu    sync.Mutex
+	specs []ActionSpec
+}
+
+// RegisterAction Declare an action, and get it to register its handlers. Registering a spec with the same UUID again replaces it.
+func (client *Client) RegisterAction(spec ActionSpec) *Action {
+	client.specs.mu.Lock()
+	replaced := false
+	for i := range client.specs.specs {
+		if client.specs.specs[i].UUID == spec.UUID {
+			client.specs.specs[i] = spec
+			replaced = true
+		}
+	}
+	if !replaced {
+		client.specs.specs = append(client.specs.specs, spec)
+	}
+	client.specs.mu.Unlock()
+
+	return client.Action(spec.UUID)
+}
+
+// ActionSpecs Get the specs registered with RegisterAction, in registration order.
+func (client *Client) ActionSpecs() []ActionSpec {
+	client.specs.mu.Lock()
+	defer client.specs.mu.Unlock()
+	return append([]ActionSpec{}, client.specs.specs...)
+}
+
+// GenerateManifest Generate the manifest of the plugin: base provides the plugin metadata (name, author, code path...), and the actions are generated from the specs registered with RegisterAction.
+// The manifest is validated without checking files, so actions used with Client.Action but not declared are reported.
+func (client *Client) GenerateManifest(base manifest.Manifest) (*manifest.Manifest, error) {
+	m := base
+	if m.SDKVersion == 0 {
+		m.SDKVersion = manifest.SDKVersion
+	}
+	m.Actions = nil
+	for _, spec := range client.ActionSpecs() {
+		m.Actions = append(m.Actions, spec.manifestAction())
+	}
+
+	if err := m.Validate(nil, client.ActionUUIDs()...); err != nil {
+		return nil, xerrors.Errorf("generated manifest is invalid: %w", err)
+	}
+	return &m, nil
+}
+
+// WriteManifest Generate the manifest of the plugin with GenerateManifest, and write it to path.
+func (client *Client) WriteManifest(path string, base manifest.Manifest) error {
+	m, err := client.GenerateManifest(base)
+	if err != nil {
+		return err
+	}
+	return m.WriteFile(path)
+}
+
+func (spec ActionSpec) manifestAction() manifest.Action {
+	supported := spec.SupportedInMultiActions
+	a := manifest.Action{
+		Controllers:             spec.Controllers,
+		Encoder:                 spec.Encoder,
+		Icon:                    spec.Icon,
+		Name:                    spec.Name,
+		PropertyInspectorPath:   spec.PropertyInspectorPath,
+		States:                  spec.States,
+		SupportedInMultiActions: &supported,
+		Tooltip:                 spec.Tooltip,
+		UUID:                    spec.UUID,
+	}
+	if len(a.States) == 0 {
+		a.States = []manifest.State{{Image: spec.Icon}}
+	}
+	if len(a.Controllers) == 0 {
+		a.Controllers = []manifest.Controller{manifest.Keypad}
+	}
+	return a
+}
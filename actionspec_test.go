@@ -0,0 +1,61 @@
+package streamdeck
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/FlowingSPDG/streamdeck/manifest"
+)
+
+var testPlugin = manifest.Manifest{
+	Author:      "Sam Rose",
+	CodePath:    "plugin.exe",
+	Description: "Test plugin",
+	Icon:        "images/plugin",
+	Name:        "Test",
+	Version:     "0.1",
+	OS:          []manifest.OS{{Platform: manifest.Windows, MinimumVersion: "10"}},
+	Software:    manifest.Software{MinimumVersion: "6.4"},
+}
+
+func TestGenerateManifest(t *testing.T) {
+	client := NewClient(context.Background(), RegistrationParams{})
+	client.RegisterAction(ActionSpec{UUID: "com.example.test.key", Name: "Old"})
+	action := client.RegisterAction(ActionSpec{UUID: "com.example.test.key", Name: "Key", Icon: "images/key"})
+	client.RegisterAction(ActionSpec{
+		UUID:        "com.example.test.dial",
+		Name:        "Dial",
+		Icon:        "images/dial",
+		Controllers: []manifest.Controller{manifest.Encoder},
+		Encoder:     &manifest.ActionEncoder{Layout: LayoutB1},
+	})
+	if action != client.Action("com.example.test.key") {
+		t.Error("RegisterAction() didn't return the action of the client")
+	}
+
+	m, err := client.GenerateManifest(testPlugin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.SDKVersion != manifest.SDKVersion || len(m.Actions) != 2 {
+		t.Fatalf("SDKVersion = %d, %d actions", m.SDKVersion, len(m.Actions))
+	}
+	key := m.Actions[0]
+	if key.Name != "Key" || len(key.States) != 1 || key.States[0].Image != "images/key" || key.Controllers[0] != manifest.Keypad {
+		t.Errorf("key action = %+v", key)
+	}
+	if dial := m.Actions[1]; dial.Encoder.Layout != LayoutB1 || dial.Controllers[0] != manifest.Encoder {
+		t.Errorf("dial action = %+v", dial)
+	}
+}
+
+func TestGenerateManifestUndeclaredAction(t *testing.T) {
+	client := NewClient(context.Background(), RegistrationParams{})
+	client.RegisterAction(ActionSpec{UUID: "com.example.test.key", Name: "Key", Icon: "images/key"})
+	client.Action("com.example.test.other")
+
+	if _, err := client.GenerateManifest(testPlugin); !errors.Is(err, manifest.ErrUnregisteredUUID) {
+		t.Errorf("GenerateManifest() = %v, want %v", err, manifest.ErrUnregisteredUUID)
+	}
+}
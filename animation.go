@@ -0,0 +1,114 @@
+package streamdeck
+
+import (
+	"image"
+	"image/draw"
+	"image/gif"
+	"io"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// DefaultFrameDelay Delay used for frames that do not specify one.
+const DefaultFrameDelay = 100 * time.Millisecond
+
+// Frame A single frame of an Animation.
+type Frame struct {
+	// Image base64 encoded image, as accepted by SetImage.
+	Image string
+	// Delay how long the frame stays on the key before the next one is shown.
+	Delay time.Duration
+}
+
+// Animation A sequence of frames to be displayed on a key.
+type Animation struct {
+	Frames []Frame
+	// LoopCount number of times the animation is played. 0 loops forever.
+	LoopCount int
+}
+
+// NewAnimation Generate new animation from images, each shown for the specified delay.
+func NewAnimation(images []image.Image, delay time.Duration) (*Animation, error) {
+	if len(images) == 0 {
+		return nil, xerrors.New("animation has no frames")
+	}
+	if delay <= 0 {
+		delay = DefaultFrameDelay
+	}
+
+	anim := &Animation{Frames: make([]Frame, 0, len(images))}
+	for i, img := range images {
+		s, err := Image(img)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to encode frame %d: %w", i, err)
+		}
+		anim.Frames = append(anim.Frames, Frame{Image: s, Delay: delay})
+	}
+	return anim, nil
+}
+
+// DecodeGIF Decode an animated GIF into an Animation. Frames are composited the same way a browser would.
+func DecodeGIF(r io.Reader) (*Animation, error) {
+	g, err := gif.DecodeAll(r)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to decode GIF: %w", err)
+	}
+	if len(g.Image) == 0 {
+		return nil, xerrors.New("GIF has no frames")
+	}
+
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	if bounds.Empty() {
+		bounds = g.Image[0].Bounds()
+	}
+	canvas := image.NewRGBA(bounds)
+
+	anim := &Animation{Frames: make([]Frame, 0, len(g.Image))}
+	switch {
+	case g.LoopCount < 0:
+		anim.LoopCount = 1
+	case g.LoopCount > 0:
+		anim.LoopCount = g.LoopCount + 1
+	}
+
+	for i, frame := range g.Image {
+		var disposal byte
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+
+		var previous *image.RGBA
+		if disposal == gif.DisposalPrevious {
+			previous = cloneRGBA(canvas)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+
+		s, err := Image(canvas)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to encode frame %d: %w", i, err)
+		}
+
+		delay := DefaultFrameDelay
+		if i < len(g.Delay) && g.Delay[i] > 0 {
+			delay = time.Duration(g.Delay[i]) * 10 * time.Millisecond
+		}
+		anim.Frames = append(anim.Frames, Frame{Image: s, Delay: delay})
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+
+	return anim, nil
+}
+
+func cloneRGBA(src *image.RGBA) *image.RGBA {
+	dst := image.NewRGBA(src.Bounds())
+	copy(dst.Pix, src.Pix)
+	return dst
+}
@@ -0,0 +1,267 @@
+package streamdeck
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"sync"
+	"testing"
+	"time"
+
+	sdcontext "github.com/FlowingSPDG/streamdeck/context"
+)
+
+func solid(c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestDecodeGIF(t *testing.T) {
+	palette := color.Palette{color.Black, color.White}
+	g := &gif.GIF{LoopCount: -1}
+	for i, c := range []color.Color{color.Black, color.White} {
+		frame := image.NewPaletted(image.Rect(0, 0, 4, 4), palette)
+		for x := 0; x < 4; x++ {
+			for y := 0; y < 4; y++ {
+				frame.Set(x, y, c)
+			}
+		}
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, (i+1)*5)
+	}
+
+	var b bytes.Buffer
+	if err := gif.EncodeAll(&b, g); err != nil {
+		t.Fatalf("failed to encode GIF: %v", err)
+	}
+
+	anim, err := DecodeGIF(&b)
+	if err != nil {
+		t.Fatalf("DecodeGIF() error = %v", err)
+	}
+	if len(anim.Frames) != 2 {
+		t.Fatalf("len(Frames) = %d, want 2", len(anim.Frames))
+	}
+	if anim.LoopCount != 1 {
+		t.Errorf("LoopCount = %d, want 1", anim.LoopCount)
+	}
+	if anim.Frames[1].Delay != 100*time.Millisecond {
+		t.Errorf("Frames[1].Delay = %v, want 100ms", anim.Frames[1].Delay)
+	}
+}
+
+// encodeAPNG builds a minimal APNG from full-size frames.
+func encodeAPNG(t *testing.T, frames []image.Image, delayMS uint16) []byte {
+	t.Helper()
+
+	var chunks []pngChunk
+	seq := uint32(0)
+	for i, img := range frames {
+		var b bytes.Buffer
+		if err := png.Encode(&b, img); err != nil {
+			t.Fatalf("failed to encode frame: %v", err)
+		}
+		cs, err := readPNGChunks(&b)
+		if err != nil {
+			t.Fatalf("failed to read frame: %v", err)
+		}
+
+		if i == 0 {
+			chunks = append(chunks, chunksOfType(cs, "IHDR")...)
+			actl := make([]byte, 8)
+			binary.BigEndian.PutUint32(actl[0:4], uint32(len(frames)))
+			chunks = append(chunks, pngChunk{"acTL", actl})
+		}
+
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:4], seq)
+		binary.BigEndian.PutUint32(fctl[4:8], uint32(img.Bounds().Dx()))
+		binary.BigEndian.PutUint32(fctl[8:12], uint32(img.Bounds().Dy()))
+		binary.BigEndian.PutUint16(fctl[20:22], delayMS)
+		binary.BigEndian.PutUint16(fctl[22:24], 1000)
+		chunks = append(chunks, pngChunk{"fcTL", fctl})
+		seq++
+
+		for _, c := range chunksOfType(cs, "IDAT") {
+			if i == 0 {
+				chunks = append(chunks, c)
+				continue
+			}
+			data := make([]byte, 4+len(c.data))
+			binary.BigEndian.PutUint32(data[0:4], seq)
+			copy(data[4:], c.data)
+			chunks = append(chunks, pngChunk{"fdAT", data})
+			seq++
+		}
+	}
+	return encodePNGChunks(chunks)
+}
+
+func TestDecodeAPNG(t *testing.T) {
+	data := encodeAPNG(t, []image.Image{solid(color.Black), solid(color.White), solid(color.Black)}, 50)
+
+	anim, err := DecodeAPNG(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("DecodeAPNG() error = %v", err)
+	}
+	if len(anim.Frames) != 3 {
+		t.Fatalf("len(Frames) = %d, want 3", len(anim.Frames))
+	}
+	if anim.LoopCount != 0 {
+		t.Errorf("LoopCount = %d, want 0", anim.LoopCount)
+	}
+	if anim.Frames[0].Delay != 50*time.Millisecond {
+		t.Errorf("Frames[0].Delay = %v, want 50ms", anim.Frames[0].Delay)
+	}
+	if anim.Frames[0].Image == anim.Frames[1].Image {
+		t.Error("frames 0 and 1 should differ")
+	}
+	if anim.Frames[0].Image != anim.Frames[2].Image {
+		t.Error("frames 0 and 2 should be identical")
+	}
+}
+
+func TestDecodeAPNGInvalid(t *testing.T) {
+	// a chunk claiming 4 GiB
+	huge := []byte(pngSignature + "\xff\xff\xff\xfbIHDR")
+	if _, err := DecodeAPNG(bytes.NewReader(huge)); err == nil {
+		t.Error("DecodeAPNG() accepted a 4 GiB chunk")
+	}
+
+	// the second frame is moved outside of the canvas
+	chunks, err := readPNGChunks(bytes.NewReader(encodeAPNG(t, []image.Image{solid(color.Black), solid(color.White)}, 50)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range chunks {
+		if c.typ == "fcTL" && binary.BigEndian.Uint32(c.data[0:4]) > 0 {
+			binary.BigEndian.PutUint32(c.data[12:16], 1)
+		}
+	}
+	if _, err := DecodeAPNG(bytes.NewReader(encodePNGChunks(chunks))); err == nil {
+		t.Error("DecodeAPNG() accepted a frame outside of the canvas")
+	}
+}
+
+func TestAnimator(t *testing.T) {
+	client := NewClient(context.Background(), RegistrationParams{})
+	a := NewAnimator(client, 1000)
+
+	mu := sync.Mutex{}
+	sent := map[string]int{}
+	a.send = func(ctx context.Context, image string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sent[sdcontext.Context(ctx)]++
+		return nil
+	}
+
+	anim, err := NewAnimation([]image.Image{solid(color.Black), solid(color.White)}, time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewAnimation() error = %v", err)
+	}
+	anim.LoopCount = 2
+
+	ctx := sdcontext.WithContext(context.Background(), "ctx1")
+	ctx = sdcontext.WithAction(ctx, "dev.example.action")
+	ctx = sdcontext.WithDevice(ctx, "device1")
+	a.Play(ctx, anim)
+
+	deadline := time.Now().Add(time.Second)
+	for a.Playing(ctx) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if a.Playing(ctx) {
+		t.Fatal("animation should have finished")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if sent["ctx1"] != 4 {
+		t.Errorf("sent %d frames, want 4", sent["ctx1"])
+	}
+}
+
+func TestAnimatorPausedDevice(t *testing.T) {
+	client := NewClient(context.Background(), RegistrationParams{})
+	a := NewAnimator(client, 1000)
+
+	anim, err := NewAnimation([]image.Image{solid(color.Black)}, time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewAnimation() error = %v", err)
+	}
+
+	ctx := sdcontext.WithContext(context.Background(), "ctx1")
+	ctx = sdcontext.WithDevice(ctx, "device1")
+	a.pauseDevice("device1")
+	a.players["ctx1"] = &player{ctx: ctx, anim: anim}
+
+	tokens := 10.0
+	frames, _ := a.step(time.Now(), &tokens)
+	if len(frames) != 0 {
+		t.Errorf("paused device sent %d frames, want 0", len(frames))
+	}
+
+	a.resumeDevice("device1")
+	frames, _ = a.step(time.Now(), &tokens)
+	if len(frames) != 1 {
+		t.Errorf("resumed device sent %d frames, want 1", len(frames))
+	}
+}
+
+func TestAnimatorStoppedBeforeSend(t *testing.T) {
+	client := NewClient(context.Background(), RegistrationParams{})
+	a := NewAnimator(client, 1000)
+
+	sent := 0
+	a.send = func(ctx context.Context, image string) error {
+		sent++
+		return nil
+	}
+
+	ctx := sdcontext.WithContext(context.Background(), "ctx1")
+	a.players["ctx1"] = &player{ctx: ctx, anim: &Animation{Frames: []Frame{{Image: "frame", Delay: time.Millisecond}}}}
+
+	tokens := 10.0
+	frames, _ := a.step(time.Now(), &tokens)
+	if len(frames) != 1 {
+		t.Fatalf("step() = %d frames, want 1", len(frames))
+	}
+	a.Stop(ctx)
+	a.sendFrames(frames)
+	if sent != 0 {
+		t.Errorf("sent %d frames after Stop, want 0", sent)
+	}
+}
+
+func TestAnimatorDefaultFrameDelay(t *testing.T) {
+	client := NewClient(context.Background(), RegistrationParams{})
+	a := NewAnimator(client, 1000)
+	a.send = func(ctx context.Context, image string) error { return nil }
+
+	anim := &Animation{Frames: []Frame{{Image: "a", Delay: 20 * time.Millisecond}, {Image: "b"}, {Image: "c", Delay: -time.Second}}}
+	ctx := sdcontext.WithContext(context.Background(), "ctx1")
+	a.Play(ctx, anim)
+	defer a.Stop(ctx)
+
+	a.mu.Lock()
+	played := a.players["ctx1"].anim
+	a.mu.Unlock()
+	for i, want := range []time.Duration{20 * time.Millisecond, DefaultFrameDelay, DefaultFrameDelay} {
+		if played.Frames[i].Delay != want {
+			t.Errorf("frame %d delay = %v, want %v", i, played.Frames[i].Delay, want)
+		}
+	}
+	if anim.Frames[1].Delay != 0 {
+		t.Error("Play() modified the animation")
+	}
+}
@@ -0,0 +1,272 @@
+package streamdeck
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	sdcontext "github.com/FlowingSPDG/streamdeck/context"
+)
+
+const (
+	// DefaultFrameBudget Maximum number of frames per second sent across all keys by default.
+	DefaultFrameBudget = 30
+
+	animatorTick = 10 * time.Millisecond
+)
+
+// Animator Plays animations on action instances.
+// All animations are driven by a single clock and share a frame budget, so many animated keys
+// can't flood the Stream Deck software. Animations stop automatically on willDisappear and pause
+// while their device is disconnected.
+type Animator struct {
+	budget int
+	send   func(ctx context.Context, image string) error
+
+	mu      sync.Mutex
+	players map[string]*player
+	paused  map[string]struct{}
+	hooked  map[string]struct{}
+	client  *Client
+	running bool
+}
+
+type player struct {
+	ctx   context.Context
+	anim  *Animation
+	frame int
+	plays int
+	next  time.Time
+	// stopped removed by Stop, willDisappear or a newer Play, its pending frames are dropped
+	stopped bool
+}
+
+// NewAnimator Get new animator for the client. frameBudget is the maximum number of frames per second sent across all keys, DefaultFrameBudget is used when it is not positive.
+func NewAnimator(client *Client, frameBudget int) *Animator {
+	if frameBudget <= 0 {
+		frameBudget = DefaultFrameBudget
+	}
+
+	a := &Animator{
+		budget:  frameBudget,
+		players: map[string]*player{},
+		paused:  map[string]struct{}{},
+		hooked:  map[string]struct{}{},
+		client:  client,
+	}
+	a.send = func(ctx context.Context, image string) error {
+		return client.SetImage(ctx, image, HardwareAndSoftware)
+	}
+
+	client.RegisterNoActionHandler(DeviceDidDisconnect, func(ctx context.Context, client *Client, event Event) error {
+		a.pauseDevice(event.Device)
+		return nil
+	})
+	client.RegisterNoActionHandler(DeviceDidConnect, func(ctx context.Context, client *Client, event Event) error {
+		a.resumeDevice(event.Device)
+		return nil
+	})
+
+	return a
+}
+
+// Play Start playing the animation on the action instance of ctx. Any animation already playing on it is replaced.
+// Frames without a positive delay are shown for DefaultFrameDelay.
+func (a *Animator) Play(ctx context.Context, anim *Animation) {
+	if anim == nil || len(anim.Frames) == 0 {
+		return
+	}
+	if sdcontext.Context(ctx) == "" {
+		panic("passed non-streamdeck context to Play")
+	}
+	anim = withFrameDelays(anim)
+
+	a.hook(sdcontext.Action(ctx))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.remove(sdcontext.Context(ctx))
+	a.players[sdcontext.Context(ctx)] = &player{
+		ctx:  ctx,
+		anim: anim,
+		next: time.Now(),
+	}
+	if !a.running {
+		a.running = true
+		go a.run()
+	}
+}
+
+// Stop Stop the animation playing on the action instance of ctx. The last displayed frame stays on the key.
+func (a *Animator) Stop(ctx context.Context) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.remove(sdcontext.Context(ctx))
+}
+
+// Playing Check if an animation is playing on the action instance of ctx.
+func (a *Animator) Playing(ctx context.Context) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, ok := a.players[sdcontext.Context(ctx)]
+	return ok
+}
+
+// remove must be called with a.mu held.
+func (a *Animator) remove(key string) {
+	if p, ok := a.players[key]; ok {
+		p.stopped = true
+		delete(a.players, key)
+	}
+}
+
+// withFrameDelays gets anim, or a copy of it with DefaultFrameDelay for frames without a positive delay.
+func withFrameDelays(anim *Animation) *Animation {
+	for i, f := range anim.Frames {
+		if f.Delay > 0 {
+			continue
+		}
+		c := *anim
+		c.Frames = slices.Clone(anim.Frames)
+		for j := i; j < len(c.Frames); j++ {
+			if c.Frames[j].Delay <= 0 {
+				c.Frames[j].Delay = DefaultFrameDelay
+			}
+		}
+		return &c
+	}
+	return anim
+}
+
+// hook registers a willDisappear handler on the action once, so its instances stop animating when they disappear.
+func (a *Animator) hook(uuid string) {
+	if uuid == "" {
+		return
+	}
+
+	a.mu.Lock()
+	_, ok := a.hooked[uuid]
+	a.hooked[uuid] = struct{}{}
+	a.mu.Unlock()
+	if ok {
+		return
+	}
+
+	a.client.Action(uuid).RegisterHandler(WillDisappear, func(ctx context.Context, client *Client, event Event) error {
+		a.Stop(ctx)
+		return nil
+	})
+}
+
+func (a *Animator) pauseDevice(device string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.paused[device] = struct{}{}
+}
+
+func (a *Animator) resumeDevice(device string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.paused, device)
+
+	now := time.Now()
+	for _, p := range a.players {
+		if sdcontext.Device(p.ctx) == device && p.next.Before(now) {
+			p.next = now
+		}
+	}
+}
+
+type pendingFrame struct {
+	p     *player
+	image string
+}
+
+func (a *Animator) run() {
+	ticker := time.NewTicker(animatorTick)
+	defer ticker.Stop()
+
+	// token bucket allowing short bursts of up to a tenth of a second worth of frames
+	refill := float64(a.budget) * animatorTick.Seconds()
+	capacity := max(1, float64(a.budget)/10)
+	tokens := capacity
+
+	for now := range ticker.C {
+		tokens = min(capacity, tokens+refill)
+
+		frames, ok := a.step(now, &tokens)
+		a.sendFrames(frames)
+		if !ok {
+			return
+		}
+	}
+}
+
+// sendFrames sends the frames collected by step, except those of players stopped since.
+func (a *Animator) sendFrames(frames []pendingFrame) {
+	for _, f := range frames {
+		a.mu.Lock()
+		stopped := f.p.stopped
+		a.mu.Unlock()
+		if stopped {
+			continue
+		}
+		if err := a.send(f.p.ctx, f.image); err != nil {
+			logger.Printf("failed to send animation frame: %v\n", err)
+		}
+	}
+}
+
+// step advances every due animation while the budget allows it. It returns false when nothing is left to play.
+func (a *Animator) step(now time.Time, tokens *float64) ([]pendingFrame, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.players) == 0 {
+		a.running = false
+		return nil, false
+	}
+
+	due := make([]string, 0, len(a.players))
+	for key, p := range a.players {
+		if _, paused := a.paused[sdcontext.Device(p.ctx)]; paused {
+			continue
+		}
+		if !p.next.After(now) {
+			due = append(due, key)
+		}
+	}
+	// longest waiting first, so keys starved by the budget catch up
+	sort.Slice(due, func(i, j int) bool {
+		return a.players[due[i]].next.Before(a.players[due[j]].next)
+	})
+
+	var frames []pendingFrame
+	for _, key := range due {
+		if *tokens < 1 {
+			break
+		}
+		*tokens--
+
+		p := a.players[key]
+		frame := p.anim.Frames[p.frame]
+		frames = append(frames, pendingFrame{p: p, image: frame.Image})
+
+		p.next = p.next.Add(frame.Delay)
+		if p.next.Before(now) {
+			p.next = now.Add(frame.Delay)
+		}
+
+		p.frame++
+		if p.frame == len(p.anim.Frames) {
+			p.frame = 0
+			p.plays++
+			if p.anim.LoopCount > 0 && p.plays >= p.anim.LoopCount {
+				delete(a.players, key)
+			}
+		}
+	}
+	return frames, true
+}
@@ -0,0 +1,258 @@
+package streamdeck
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+const (
+	// maxPNGChunkLength largest chunk read, well above what a key image needs; the PNG limit of 2^31-1 would allow 2 GiB allocations.
+	maxPNGChunkLength = 16 << 20
+	// maxAPNGSize largest width and height of an animation canvas.
+	maxAPNGSize = 4096
+)
+
+// APNG frame control dispose_op / blend_op values.
+const (
+	apngDisposeNone       = 0
+	apngDisposeBackground = 1
+	apngDisposePrevious   = 2
+
+	apngBlendSource = 0
+)
+
+type pngChunk struct {
+	typ  string
+	data []byte
+}
+
+type apngFrame struct {
+	width, height int
+	x, y          int
+	delay         time.Duration
+	dispose       byte
+	blend         byte
+	data          [][]byte
+}
+
+// DecodeAPNG Decode an animated PNG into an Animation. A PNG without animation control is decoded as a single frame.
+func DecodeAPNG(r io.Reader) (*Animation, error) {
+	chunks, err := readPNGChunks(r)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read PNG: %w", err)
+	}
+
+	var (
+		ihdr     []byte
+		shared   []pngChunk // chunks required to decode every frame, e.g. PLTE and tRNS
+		frames   []*apngFrame
+		current  *apngFrame
+		plays    = -1
+		seenIDAT bool
+	)
+	for _, c := range chunks {
+		switch c.typ {
+		case "IHDR":
+			ihdr = c.data
+		case "acTL":
+			if len(c.data) < 8 {
+				return nil, xerrors.New("invalid acTL chunk")
+			}
+			plays = int(binary.BigEndian.Uint32(c.data[4:8]))
+		case "fcTL":
+			f, err := parseFCTL(c.data)
+			if err != nil {
+				return nil, err
+			}
+			frames = append(frames, f)
+			current = f
+		case "IDAT":
+			seenIDAT = true
+			// The default image is only part of the animation when fcTL precedes it.
+			if current != nil {
+				current.data = append(current.data, c.data)
+			}
+		case "fdAT":
+			if len(c.data) < 4 {
+				return nil, xerrors.New("invalid fdAT chunk")
+			}
+			if current == nil {
+				return nil, xerrors.New("fdAT chunk without fcTL")
+			}
+			current.data = append(current.data, c.data[4:])
+		case "IEND":
+		default:
+			if !seenIDAT {
+				shared = append(shared, c)
+			}
+		}
+	}
+
+	if ihdr == nil || len(ihdr) < 13 {
+		return nil, xerrors.New("missing IHDR chunk")
+	}
+	width := int(binary.BigEndian.Uint32(ihdr[0:4]))
+	height := int(binary.BigEndian.Uint32(ihdr[4:8]))
+	if width <= 0 || height <= 0 || width > maxAPNGSize || height > maxAPNGSize {
+		return nil, xerrors.Errorf("invalid image size %dx%d", width, height)
+	}
+
+	// Not animated. Fall back to the default image.
+	if plays < 0 || len(frames) == 0 {
+		img, err := png.Decode(bytes.NewReader(encodePNGChunks(append(append([]pngChunk{{"IHDR", ihdr}}, shared...), chunksOfType(chunks, "IDAT")...))))
+		if err != nil {
+			return nil, xerrors.Errorf("failed to decode PNG: %w", err)
+		}
+		return NewAnimation([]image.Image{img}, DefaultFrameDelay)
+	}
+
+	for i, f := range frames {
+		if f.width <= 0 || f.height <= 0 || f.x < 0 || f.y < 0 || f.x+f.width > width || f.y+f.height > height {
+			return nil, xerrors.Errorf("frame %d at %d,%d of %dx%d is outside the %dx%d canvas", i, f.x, f.y, f.width, f.height, width, height)
+		}
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	anim := &Animation{Frames: make([]Frame, 0, len(frames)), LoopCount: plays}
+	for i, f := range frames {
+		frameIHDR := make([]byte, len(ihdr))
+		copy(frameIHDR, ihdr)
+		binary.BigEndian.PutUint32(frameIHDR[0:4], uint32(f.width))
+		binary.BigEndian.PutUint32(frameIHDR[4:8], uint32(f.height))
+
+		cs := append([]pngChunk{{"IHDR", frameIHDR}}, shared...)
+		for _, d := range f.data {
+			cs = append(cs, pngChunk{"IDAT", d})
+		}
+		img, err := png.Decode(bytes.NewReader(encodePNGChunks(cs)))
+		if err != nil {
+			return nil, xerrors.Errorf("failed to decode frame %d: %w", i, err)
+		}
+
+		var previous *image.RGBA
+		if f.dispose == apngDisposePrevious {
+			previous = cloneRGBA(canvas)
+		}
+
+		rect := image.Rect(f.x, f.y, f.x+f.width, f.y+f.height)
+		op := draw.Over
+		if f.blend == apngBlendSource {
+			op = draw.Src
+		}
+		draw.Draw(canvas, rect, img, img.Bounds().Min, op)
+
+		s, err := Image(canvas)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to encode frame %d: %w", i, err)
+		}
+		anim.Frames = append(anim.Frames, Frame{Image: s, Delay: f.delay})
+
+		switch f.dispose {
+		case apngDisposeBackground:
+			draw.Draw(canvas, rect, image.Transparent, image.Point{}, draw.Src)
+		case apngDisposePrevious:
+			canvas = previous
+		}
+	}
+
+	return anim, nil
+}
+
+func parseFCTL(data []byte) (*apngFrame, error) {
+	if len(data) < 26 {
+		return nil, xerrors.New("invalid fcTL chunk")
+	}
+	f := &apngFrame{
+		width:   int(binary.BigEndian.Uint32(data[4:8])),
+		height:  int(binary.BigEndian.Uint32(data[8:12])),
+		x:       int(binary.BigEndian.Uint32(data[12:16])),
+		y:       int(binary.BigEndian.Uint32(data[16:20])),
+		dispose: data[24],
+		blend:   data[25],
+	}
+
+	num := binary.BigEndian.Uint16(data[20:22])
+	den := binary.BigEndian.Uint16(data[22:24])
+	if den == 0 {
+		den = 100
+	}
+	f.delay = time.Duration(num) * time.Second / time.Duration(den)
+	if f.delay <= 0 {
+		f.delay = DefaultFrameDelay
+	}
+	return f, nil
+}
+
+func readPNGChunks(r io.Reader) ([]pngChunk, error) {
+	sig := make([]byte, len(pngSignature))
+	if _, err := io.ReadFull(r, sig); err != nil {
+		return nil, err
+	}
+	if string(sig) != pngSignature {
+		return nil, xerrors.New("not a PNG file")
+	}
+
+	var chunks []pngChunk
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, err
+		}
+		length := binary.BigEndian.Uint32(header[0:4])
+		typ := string(header[4:8])
+		if length > maxPNGChunkLength {
+			return nil, xerrors.Errorf("%s chunk of %d bytes is too large", typ, length)
+		}
+
+		// data followed by CRC
+		data := make([]byte, int(length)+4)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, pngChunk{typ: typ, data: data[:length]})
+
+		if typ == "IEND" {
+			return chunks, nil
+		}
+	}
+}
+
+func chunksOfType(chunks []pngChunk, typ string) []pngChunk {
+	var ret []pngChunk
+	for _, c := range chunks {
+		if c.typ == typ {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+func encodePNGChunks(chunks []pngChunk) []byte {
+	var b bytes.Buffer
+	b.WriteString(pngSignature)
+	for _, c := range append(chunks, pngChunk{typ: "IEND"}) {
+		var n [4]byte
+		binary.BigEndian.PutUint32(n[:], uint32(len(c.data)))
+		b.Write(n[:])
+
+		crc := crc32.NewIEEE()
+		crc.Write([]byte(c.typ))
+		crc.Write(c.data)
+
+		b.WriteString(c.typ)
+		b.Write(c.data)
+		binary.BigEndian.PutUint32(n[:], crc.Sum32())
+		b.Write(n[:])
+	}
+	return b.Bytes()
+}
@@ -0,0 +1,196 @@
+package streamdeck
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+
+	sdcontext "github.com/FlowingSPDG/streamdeck/context"
+	"github.com/FlowingSPDG/streamdeck/manifest"
+	"golang.org/x/xerrors"
+)
+
+// AppHandler Called when a monitored application launches (running is true) or terminates.
+type AppHandler func(ctx context.Context, client *Client, application string, running bool) error
+
+// AppIndicator How the instances of an action show whether their application is running.
+type AppIndicator struct {
+	// RunningState, NotRunningState states of the instances while the application runs and while it doesn't.
+	// The state is not changed when both are 0.
+	RunningState, NotRunningState int
+	// NotRunningImage image shown while the application doesn't run, as passed to SetImage. The image of the state is shown again when it launches.
+	NotRunningImage string
+}
+
+// Apps Tracks which of the applications the plugin monitors are running, from applicationDidLaunch and applicationDidTerminate.
+// The Stream Deck software only sends them for the applications listed in ApplicationsToMonitor of the manifest, for the platform.
+type Apps struct {
+	monitored []string
+
+	mu       sync.Mutex
+	running  map[string]bool
+	handlers map[string][]AppHandler
+}
+
+// NewApps Track the applications to monitor of the manifest, for the platform of the registration info of the client.
+func NewApps(client *Client, monitor *manifest.ApplicationsToMonitor) *Apps {
+	a := &Apps{
+		running:  map[string]bool{},
+		handlers: map[string][]AppHandler{},
+	}
+	if monitor != nil {
+		switch manifest.Platform(client.params.Info.Application.Platform) {
+		case manifest.Mac:
+			a.monitored = monitor.Mac
+		case manifest.Windows:
+			a.monitored = monitor.Windows
+		default:
+			a.monitored = append(slices.Clone(monitor.Mac), monitor.Windows...)
+		}
+	}
+
+	client.RegisterNoActionHandler(ApplicationDidLaunch, func(ctx context.Context, client *Client, event Event) error {
+		var p ApplicationDidLaunchPayload
+		if err := event.UnmarshalPayload(&p); err != nil {
+			return xerrors.Errorf("failed to unmarshal %s payload: %w", ApplicationDidLaunch, err)
+		}
+		return a.set(ctx, client, p.Application, true)
+	})
+	client.RegisterNoActionHandler(ApplicationDidTerminate, func(ctx context.Context, client *Client, event Event) error {
+		var p ApplicationDidTerminatePayload
+		if err := event.UnmarshalPayload(&p); err != nil {
+			return xerrors.Errorf("failed to unmarshal %s payload: %w", ApplicationDidTerminate, err)
+		}
+		return a.set(ctx, client, p.Application, false)
+	})
+
+	return a
+}
+
+// Monitored Get the applications monitored on this platform.
+func (a *Apps) Monitored() []string {
+	return slices.Clone(a.monitored)
+}
+
+// Check Check that the application is listed in ApplicationsToMonitor, otherwise the Stream Deck software never reports it.
+func (a *Apps) Check(application string) error {
+	if !slices.Contains(a.monitored, application) {
+		return xerrors.Errorf("%w: %q", ErrAppNotMonitored, application)
+	}
+	return nil
+}
+
+// IsRunning Check if the application is running.
+func (a *Apps) IsRunning(application string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.running[application]
+}
+
+// Running Get the monitored applications that are running.
+func (a *Apps) Running() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var running []string
+	for _, app := range a.monitored {
+		if a.running[app] {
+			running = append(running, app)
+		}
+	}
+	return running
+}
+
+// Subscribe Register a handler called when the application launches or terminates. It must be monitored, see Check.
+func (a *Apps) Subscribe(application string, handler AppHandler) error {
+	if err := a.Check(application); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.handlers[application] = append(a.handlers[application], handler)
+	return nil
+}
+
+// Indicate Show on the instances of the action whether the application is running, with their state or image.
+// Instances are updated when they appear and whenever the application launches or terminates.
+func (a *Apps) Indicate(action *Action, application string, indicator AppIndicator) error {
+	var mu sync.Mutex
+	instances := map[string]context.Context{}
+
+	update := func(ctx context.Context, client *Client, running bool) error {
+		if indicator.RunningState != 0 || indicator.NotRunningState != 0 {
+			state := indicator.NotRunningState
+			if running {
+				state = indicator.RunningState
+			}
+			if err := client.SetState(ctx, state); err != nil {
+				return err
+			}
+		}
+		if indicator.NotRunningImage != "" {
+			image := indicator.NotRunningImage
+			if running {
+				image = ""
+			}
+			return client.SetImage(ctx, image, HardwareAndSoftware)
+		}
+		return nil
+	}
+
+	err := a.Subscribe(application, func(_ context.Context, client *Client, _ string, running bool) error {
+		mu.Lock()
+		ctxs := make([]context.Context, 0, len(instances))
+		for _, ctx := range instances {
+			ctxs = append(ctxs, ctx)
+		}
+		mu.Unlock()
+
+		var errs []error
+		for _, ctx := range ctxs {
+			if err := update(ctx, client, running); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	})
+	if err != nil {
+		return err
+	}
+
+	action.RegisterHandler(WillAppear, func(ctx context.Context, client *Client, event Event) error {
+		mu.Lock()
+		instances[sdcontext.Context(ctx)] = ctx
+		mu.Unlock()
+		return update(ctx, client, a.IsRunning(application))
+	})
+	action.RegisterHandler(WillDisappear, func(ctx context.Context, client *Client, event Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(instances, sdcontext.Context(ctx))
+		return nil
+	})
+	return nil
+}
+
+func (a *Apps) set(ctx context.Context, client *Client, application string, running bool) error {
+	a.mu.Lock()
+	changed := a.running[application] != running
+	if running {
+		a.running[application] = true
+	} else {
+		delete(a.running, application)
+	}
+	handlers := a.handlers[application]
+	a.mu.Unlock()
+
+	if !changed {
+		return nil
+	}
+	for _, h := range handlers {
+		if err := h(ctx, client, application, running); err != nil {
+			return err
+		}
+	}
+	return nil
+}
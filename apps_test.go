@@ -0,0 +1,65 @@
+package streamdeck_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/FlowingSPDG/streamdeck"
+	"github.com/FlowingSPDG/streamdeck/manifest"
+	"github.com/FlowingSPDG/streamdeck/sdtest"
+)
+
+func TestApps(t *testing.T) {
+	sim := sdtest.NewSimulator(t)
+	client := streamdeck.NewClient(context.Background(), sim.RegistrationParams())
+	apps := streamdeck.NewApps(client, &manifest.ApplicationsToMonitor{
+		Mac:     []string{"com.example.app"},
+		Windows: []string{"com.example.app"},
+	})
+	if err := apps.Subscribe("com.example.other", nil); !errors.Is(err, streamdeck.ErrAppNotMonitored) {
+		t.Errorf("Subscribe(other) = %v, want ErrAppNotMonitored", err)
+	}
+	changes := make(chan bool, 2)
+	err := apps.Subscribe("com.example.app", func(ctx context.Context, client *streamdeck.Client, application string, running bool) error {
+		changes <- running
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = apps.Indicate(client.Action("com.example.launch"), "com.example.app", streamdeck.AppIndicator{
+		RunningState:    0,
+		NotRunningState: 1,
+		NotRunningImage: "data:image/svg+xml;charset=utf8,<svg/>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim.RunClient(client)
+
+	inst := sim.Place("com.example.launch", sim.Deck().Devices()[0].ID, 0, 0, nil)
+	sim.ExpectSetState(inst.Context, 1)
+	sim.ExpectSetImage(inst.Context)
+
+	sim.ApplicationDidLaunch("com.example.app")
+	if !<-changes {
+		t.Error("running = false after launch")
+	}
+	if !apps.IsRunning("com.example.app") || len(apps.Running()) != 1 {
+		t.Errorf("IsRunning() = false, Running() = %v after launch", apps.Running())
+	}
+	sim.ExpectSetState(inst.Context, 0)
+	if got := sim.ExpectSetImage(inst.Context); got != "" {
+		t.Errorf("image = %q while running, want the image of the state", got)
+	}
+
+	sim.ApplicationDidTerminate("com.example.app")
+	if <-changes {
+		t.Error("running = true after terminate")
+	}
+	sim.ExpectSetState(inst.Context, 1)
+	if apps.IsRunning("com.example.app") {
+		t.Error("IsRunning() = true after terminate")
+	}
+}
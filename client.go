@@ -9,12 +9,12 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"sort"
 	"sync"
 	"time"
 
 	sdcontext "github.com/FlowingSPDG/streamdeck/context"
 	"github.com/coder/websocket"
-	"github.com/coder/websocket/wsjson"
 	"github.com/puzpuzpuz/xsync/v3"
 	"golang.org/x/xerrors"
 )
@@ -42,6 +42,12 @@ type Client struct {
 	handlers  *eventHandlers
 	done      chan struct{}
 	sendMutex *sync.Mutex
+	recorder  *Recorder
+	specs     *actionSpecs
+	// inspectors contexts whose property inspector is open
+	inspectors *xsync.MapOf[string, struct{}]
+	// multiActions contexts of instances in multi-actions
+	multiActions *xsync.MapOf[string, struct{}]
 }
 
 type actions struct {
@@ -59,8 +65,11 @@ func NewClient(ctx context.Context, params RegistrationParams) *Client {
 		handlers: &eventHandlers{
 			m: xsync.NewMapOf[string, *eventHandlerSlice](),
 		},
-		done:      make(chan struct{}),
-		sendMutex: &sync.Mutex{},
+		done:         make(chan struct{}),
+		sendMutex:    &sync.Mutex{},
+		specs:        &actionSpecs{},
+		inspectors:   xsync.NewMapOf[string, struct{}](),
+		multiActions: xsync.NewMapOf[string, struct{}](),
 	}
 }
 
@@ -82,6 +91,17 @@ func (client *Client) Action(uuid string) *Action {
 	return v
 }
 
+// ActionUUIDs Get the UUIDs of the actions of the client, sorted.
+func (client *Client) ActionUUIDs() []string {
+	uuids := make([]string, 0, client.actions.m.Size())
+	client.actions.m.Range(func(uuid string, _ *Action) bool {
+		uuids = append(uuids, uuid)
+		return true
+	})
+	sort.Strings(uuids)
+	return uuids
+}
+
 // RegisterNoActionHandler register event handler with no action such as "applicationDidLaunch".
 func (client *Client) RegisterNoActionHandler(eventName string, handler EventHandler) {
 	eh, _ := client.handlers.m.LoadOrStore(eventName, &eventHandlerSlice{
@@ -117,6 +137,7 @@ func (client *Client) Run(ctx context.Context) error {
 				logger.Printf("read error: %v\n", err)
 				return
 			}
+			client.record(Inbound, message)
 
 			event := Event{}
 			if err := json.Unmarshal(message, &event); err != nil {
@@ -125,10 +146,13 @@ func (client *Client) Run(ctx context.Context) error {
 			}
 
 			logger.Println("recv: ", string(message))
+			client.trackPropertyInspector(event)
+			client.trackMultiAction(event)
 
 			ctx := sdcontext.WithContext(ctx, event.Context)
 			ctx = sdcontext.WithDevice(ctx, event.Device)
 			ctx = sdcontext.WithAction(ctx, event.Action)
+			ctx = sdcontext.WithMultiAction(ctx, client.InMultiAction(ctx))
 
 			if event.Action == "" {
 				eh, ok := client.handlers.m.Load(event.Event)
@@ -181,8 +205,14 @@ func (client *Client) send(ctx context.Context, event Event) error {
 	client.sendMutex.Lock()
 	defer client.sendMutex.Unlock()
 
+	b, err := json.Marshal(event)
+	if err != nil {
+		return xerrors.Errorf("%w: %v", ErrJSONMarshal, err)
+	}
+	client.record(Outbound, b)
+
 	// WebSocketでJSON送信
-	if err := wsjson.Write(ctx, client.c, event); err != nil {
+	if err := client.c.Write(ctx, websocket.MessageText, b); err != nil {
 		return xerrors.Errorf("%w: %v", ErrWriteFailed, err)
 	}
 	return nil
@@ -220,6 +250,10 @@ func (client *Client) LogMessage(ctx context.Context, message string) error {
 
 // SetTitle Dynamically change the title of an instance of an action.
 func (client *Client) SetTitle(ctx context.Context, title string, target Target, state ...int) error {
+	if client.InMultiAction(ctx) {
+		logger.Printf("setTitle ignored: %s is in a multi-action\n", sdcontext.Context(ctx))
+		return nil
+	}
 	payload := SetTitlePayload{Title: title, Target: target}
 	if len(state) > 0 {
 		payload.State = state[0]
@@ -229,6 +263,10 @@ func (client *Client) SetTitle(ctx context.Context, title string, target Target,
 
 // SetImage Dynamically change the image displayed by an instance of an action.
 func (client *Client) SetImage(ctx context.Context, base64image string, target Target, state ...int) error {
+	if client.InMultiAction(ctx) {
+		logger.Printf("setImage ignored: %s is in a multi-action\n", sdcontext.Context(ctx))
+		return nil
+	}
 	payload := SetImagePayload{Base64Image: base64image, Target: target}
 	if len(state) > 0 {
 		payload.State = state[0]
@@ -237,11 +275,17 @@ func (client *Client) SetImage(ctx context.Context, base64image string, target T
 }
 
 // SetFeedback The plugin can send a setFeedback event to the Stream Deck application to dynamically change properties of items on the Stream Deck + touch display layout.
+// payload may be any value marshalled to the feedback object, see SetLayoutFeedback for the typed layout payloads.
 func (client *Client) SetFeedback(ctx context.Context, payload any) error {
 	return client.send(ctx, NewEvent(ctx, SetFeedback, payload))
 }
 
-// SetFeedbackLayout Sets the layout associated with an action instance.
+// SetLayoutFeedback Send setFeedback with a typed layout payload such as LayoutB1Feedback, or CustomFeedback. Images of pixmap items are encoded before sending.
+func (client *Client) SetLayoutFeedback(ctx context.Context, feedback Feedback) error {
+	return client.send(ctx, NewEvent(ctx, SetFeedback, feedback))
+}
+
+// SetFeedbackLayout Sets the layout associated with an action instance. layout is one of the built-in layouts such as LayoutB1, or the path to a custom layout JSON file.
 func (client *Client) SetFeedbackLayout(ctx context.Context, layout string) error {
 	return client.send(ctx, NewEvent(ctx, SetFeedbackLayout, SetFeedbackLayoutPayload{Layout: layout}))
 }
@@ -266,13 +310,22 @@ func (client *Client) SetState(ctx context.Context, state int) error {
 	return client.send(ctx, NewEvent(ctx, SetState, SetStatePayload{State: state}))
 }
 
-// SwitchToProfile Switch to one of the preconfigured read-only profiles.
+// SwitchToProfile Switch the device of ctx to one of the preconfigured read-only profiles, by its name in the manifest.
+// page is the zero-based index of the page to show. An empty profile switches back to the profile that was shown before.
+// Use ProfileSwitcher to check the profile and remember the previous ones.
 func (client *Client) SwitchToProfile(ctx context.Context, profile string, page ...int) error {
 	payload := SwitchProfilePayload{Profile: profile}
 	if len(page) > 0 {
-		payload.Page = page[0]
+		p := page[0]
+		payload.Page = &p
 	}
-	return client.send(ctx, NewEvent(ctx, SwitchToProfile, payload))
+	// the profile is switched for the plugin, not for an action instance
+	return client.send(ctx, Event{
+		Event:   SwitchToProfile,
+		Context: client.params.PluginUUID,
+		Device:  sdcontext.Device(ctx),
+		Payload: payload,
+	})
 }
 
 // SendToPropertyInspector Send a payload to the Property Inspector.
@@ -280,9 +333,12 @@ func (client *Client) SendToPropertyInspector(ctx context.Context, payload any)
 	return client.send(ctx, NewEvent(ctx, SendToPropertyInspector, payload))
 }
 
-// SendToPlugin Send a payload to the plugin.
+// SendToPlugin Send a payload to the plugin, as the property inspector of the action instance of ctx would.
+// sendToPlugin is sent by property inspectors: use PropertyInspectorClient to act as one.
 func (client *Client) SendToPlugin(ctx context.Context, action string, payload any) error {
-	return client.send(ctx, NewEvent(ctx, SendToPlugin, payload))
+	event := NewEvent(ctx, SendToPlugin, payload)
+	event.Action = action
+	return client.send(ctx, event)
 }
 
 // Close close client
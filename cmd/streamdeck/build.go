@@ -0,0 +1,162 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/FlowingSPDG/streamdeck/manifest"
+	"golang.org/x/xerrors"
+)
+
+// buildOptions options shared by build and pack.
+type buildOptions struct {
+	// dir directory of the plugin Go package and its manifest.json
+	dir string
+	// out .sdPlugin directory
+	out string
+	// uuid plugin UUID, the name of the .sdPlugin directory
+	uuid string
+	// name binary name without extension
+	name string
+}
+
+func (o *buildOptions) flags(fs *flag.FlagSet) {
+	fs.StringVar(&o.dir, "dir", ".", "directory of the plugin Go package and its manifest.json")
+	fs.StringVar(&o.out, "out", "", "output .sdPlugin directory. <uuid>.sdPlugin in -dir by default")
+	fs.StringVar(&o.uuid, "uuid", "", "plugin UUID. the UUID of the manifest, or of its first action, by default")
+	fs.StringVar(&o.name, "name", "", "binary name without extension. derived from the code path of the manifest by default")
+}
+
+// resolve fills the options left empty from the manifest.
+func (o *buildOptions) resolve(m *manifest.Manifest) error {
+	if o.uuid == "" {
+		o.uuid = m.UUID
+	}
+	if o.uuid == "" && len(m.Actions) > 0 {
+		o.uuid = m.Actions[0].UUID
+	}
+	if o.uuid == "" {
+		return xerrors.New("plugin UUID is unknown, set -uuid")
+	}
+	if o.out == "" {
+		o.out = filepath.Join(o.dir, o.uuid+".sdPlugin")
+	}
+
+	if o.name == "" {
+		for _, p := range []string{m.CodePathWin, m.CodePath, m.CodePathMac} {
+			if p != "" {
+				o.name = strings.TrimSuffix(filepath.Base(p), ".exe")
+				break
+			}
+		}
+	}
+	if o.name == "" {
+		abs, err := filepath.Abs(o.dir)
+		if err != nil {
+			return err
+		}
+		o.name = filepath.Base(abs)
+	}
+	return nil
+}
+
+func loadManifest(o *buildOptions) (*manifest.Manifest, error) {
+	m, err := manifest.Load(filepath.Join(o.dir, manifest.FileName))
+	if err != nil {
+		return nil, err
+	}
+	if err := o.resolve(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func runBuild(args []string) error {
+	fs := flag.NewFlagSet("build", flag.ContinueOnError)
+	var o buildOptions
+	o.flags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	m, err := loadManifest(&o)
+	if err != nil {
+		return err
+	}
+	return build(o, m)
+}
+
+// build cross-compiles the plugin for the platforms of the manifest into the .sdPlugin directory,
+// and writes the manifest there with the code paths of the binaries.
+func build(o buildOptions, m *manifest.Manifest) error {
+	if err := os.MkdirAll(o.out, 0o755); err != nil {
+		return xerrors.Errorf("failed to create %s: %w", o.out, err)
+	}
+
+	windows, mac := len(m.OS) == 0, len(m.OS) == 0
+	for _, p := range m.OS {
+		switch p.Platform {
+		case manifest.Windows:
+			windows = true
+		case manifest.Mac:
+			mac = true
+		}
+	}
+
+	m.CodePath, m.CodePathWin, m.CodePathMac = "", "", ""
+	if windows {
+		name := o.name + ".exe"
+		if err := goBuild(o.dir, "windows", "amd64", filepath.Join(o.out, name)); err != nil {
+			return err
+		}
+		m.CodePathWin = name
+	}
+	if mac {
+		tmp, err := os.MkdirTemp("", "streamdeck-build")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+
+		var thin []string
+		for _, arch := range []string{"amd64", "arm64"} {
+			p := filepath.Join(tmp, o.name+"-"+arch)
+			if err := goBuild(o.dir, "darwin", arch, p); err != nil {
+				return err
+			}
+			thin = append(thin, p)
+		}
+		if err := writeUniversal(filepath.Join(o.out, o.name), thin...); err != nil {
+			return err
+		}
+		m.CodePathMac = o.name
+	}
+
+	// CodePath is the fallback of the platform specific code paths
+	m.CodePath = m.CodePathWin
+	if m.CodePath == "" {
+		m.CodePath = m.CodePathMac
+	}
+	return m.WriteFile(filepath.Join(o.out, manifest.FileName))
+}
+
+func goBuild(dir, goos, goarch, out string) error {
+	abs, err := filepath.Abs(out)
+	if err != nil {
+		return err
+	}
+	log.Printf("building %s/%s", goos, goarch)
+	cmd := exec.Command("go", "build", "-trimpath", "-ldflags=-s -w", "-o", abs, ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOOS="+goos, "GOARCH="+goarch, "CGO_ENABLED=0")
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return xerrors.Errorf("failed to build %s/%s: %w", goos, goarch, err)
+	}
+	return nil
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/FlowingSPDG/streamdeck"
+	"github.com/FlowingSPDG/streamdeck/manifest"
+	"github.com/FlowingSPDG/streamdeck/sdtest"
+	"golang.org/x/xerrors"
+)
+
+// devRegisterTimeout time waited for the plugin to register after it is launched, builds included.
+const devRegisterTimeout = 10 * time.Second
+
+func runDev(args []string) error {
+	fs := flag.NewFlagSet("dev", flag.ContinueOnError)
+	dir := fs.String("dir", ".", "directory of the plugin Go package and its manifest.json")
+	addr := fs.String("addr", "127.0.0.1:0", "address the fake Stream Deck software listens on")
+	interval := fs.Duration("interval", 500*time.Millisecond, "interval the sources are checked for changes at")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	m, err := manifest.Load(filepath.Join(*dir, manifest.FileName))
+	if err != nil {
+		return err
+	}
+	info := sdtest.DefaultInfo()
+	info.Plugin = streamdeck.Plugin{UUID: m.UUID, Version: m.Version}
+	sim, err := sdtest.StartSimulator(*addr, info)
+	if err != nil {
+		return err
+	}
+	defer sim.Close()
+	sim.Timeout = devRegisterTimeout
+	sim.OnMessage(func(event streamdeck.Event) {
+		log.Printf("plugin: %s %s", event.Event, event.Context)
+	})
+	for _, k := range placeActions(sim.Deck(), m) {
+		log.Printf("placed %s on %s %d,%d", k.Action, k.Device, k.Coordinates.Column, k.Coordinates.Row)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	d := &devRunner{dir: *dir, sim: sim}
+	return d.run(ctx, *interval)
+}
+
+// devRunner builds and runs a plugin against a simulator, restarting it when its sources change.
+type devRunner struct {
+	dir string
+	sim *sdtest.Simulator
+
+	bin string
+	cmd *exec.Cmd
+}
+
+func (d *devRunner) run(ctx context.Context, interval time.Duration) error {
+	tmp, err := os.MkdirTemp("", "streamdeck-dev")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	d.bin = filepath.Join(tmp, "plugin")
+	if runtime.GOOS == "windows" {
+		d.bin += ".exe"
+	}
+	defer d.stop()
+
+	sources, err := sourceModTimes(d.dir)
+	if err != nil {
+		return err
+	}
+	d.restart()
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+		current, err := sourceModTimes(d.dir)
+		if err != nil {
+			log.Print(err)
+			continue
+		}
+		if !sameModTimes(sources, current) {
+			sources = current
+			log.Printf("sources changed, restarting")
+			d.restart()
+		}
+	}
+}
+
+// restart rebuilds the plugin and replaces the running one. The running plugin is kept when the build fails.
+func (d *devRunner) restart() {
+	if err := goBuild(d.dir, runtime.GOOS, runtime.GOARCH, d.bin); err != nil {
+		log.Print(err)
+		return
+	}
+	d.stop()
+
+	d.cmd = exec.Command(d.bin, d.sim.Args()...)
+	d.cmd.Dir = d.dir
+	d.cmd.Stdout = os.Stdout
+	d.cmd.Stderr = os.Stderr
+	if err := d.cmd.Start(); err != nil {
+		log.Printf("failed to start the plugin: %v", err)
+		d.cmd = nil
+		return
+	}
+	go func(cmd *exec.Cmd) {
+		// plugins replaced by a restart are killed, only those exiting by themselves are reported
+		cmd.Wait()
+		if cmd.ProcessState.Exited() {
+			log.Printf("plugin exited with code %d", cmd.ProcessState.ExitCode())
+		}
+	}(d.cmd)
+
+	if err := d.sim.WaitRegistered(); err != nil {
+		log.Print(err)
+		return
+	}
+	log.Printf("plugin registered")
+	if err := appearAll(d.sim); err != nil {
+		log.Print(err)
+	}
+}
+
+func (d *devRunner) stop() {
+	if d.cmd == nil {
+		return
+	}
+	d.cmd.Process.Kill()
+	// wait for the connection of the stopped plugin to be closed, so that the next one is waited for
+	for deadline := time.Now().Add(devRegisterTimeout); d.sim.Connected() && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	d.cmd = nil
+}
+
+// placeActions places every action of the manifest on the deck: actions supporting encoders on the dials
+// of the Stream Deck + devices, the others on the keys of the other devices, in order.
+func placeActions(deck *sdtest.Deck, m *manifest.Manifest) []sdtest.Key {
+	var placed []sdtest.Key
+	next := map[string]int{}
+	for _, a := range m.Actions {
+		controller := sdtest.Keypad
+		if slices.Contains(a.Controllers, manifest.Encoder) {
+			controller = sdtest.Encoder
+		}
+
+		var k sdtest.Key
+		var err error = xerrors.Errorf("no free %s", controller)
+		for _, dev := range deck.Devices() {
+			if (controller == sdtest.Encoder) != (dev.Type == streamdeck.StreamDeckPlus) {
+				continue
+			}
+			i, size := next[dev.ID+controller], dev.Columns*dev.Rows
+			if controller == sdtest.Encoder {
+				size = dev.Encoders
+			}
+			if i >= size {
+				continue
+			}
+			next[dev.ID+controller]++
+			column, row := i, 0
+			if controller == sdtest.Keypad {
+				column, row = i%dev.Columns, i/dev.Columns
+			}
+			k, err = deck.Place(a.UUID, dev.ID, controller, column, row, nil)
+			break
+		}
+		if err != nil {
+			log.Printf("failed to place %s: %v", a.UUID, err)
+			continue
+		}
+		deck.Update(k.Context, func(k *sdtest.Key) {
+			k.States = max(len(a.States), 1)
+		})
+		placed = append(placed, k)
+	}
+	return placed
+}
+
+// appearAll sends deviceDidConnect for every device, then willAppear for every instance on the deck,
+// like the Stream Deck software does when a plugin starts.
+func appearAll(sim *sdtest.Simulator) error {
+	for _, dev := range sim.Deck().Devices() {
+		err := sim.Send(streamdeck.Event{
+			Event:  streamdeck.DeviceDidConnect,
+			Device: dev.ID,
+			DeviceInfo: streamdeck.DeviceInfo{
+				DeviceName: dev.Name,
+				Type:       dev.Type,
+				Size:       streamdeck.DeviceSize{Columns: dev.Columns, Rows: dev.Rows},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, k := range sim.Deck().Keys() {
+		err := sim.Send(streamdeck.Event{
+			Action:  k.Action,
+			Event:   streamdeck.WillAppear,
+			Context: k.Context,
+			Device:  k.Device,
+			Payload: streamdeck.WillAppearPayload[json.RawMessage]{
+				Settings:        k.Settings,
+				Coordinates:     k.Coordinates,
+				State:           k.State,
+				IsInMultiAction: k.IsInMultiAction,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sourceModTimes modification times of the Go sources and module files of the plugin, keyed by path.
+func sourceModTimes(dir string) (map[string]time.Time, error) {
+	times := map[string]time.Time{}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		// editors create and remove temporary files while the sources are walked
+		if errors.Is(err, fs.ErrNotExist) && p != dir {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != dir && skipAsset(d.Name(), true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(p) != ".go" && d.Name() != "go.mod" && d.Name() != "go.sum" {
+			return nil
+		}
+		info, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		times[p] = info.ModTime()
+		return nil
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to check the sources of %s: %w", dir, err)
+	}
+	return times, nil
+}
+
+func sameModTimes(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for p, t := range a {
+		if !t.Equal(b[p]) {
+			return false
+		}
+	}
+	return true
+}
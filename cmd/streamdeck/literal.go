@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/FlowingSPDG/streamdeck"
+	"github.com/FlowingSPDG/streamdeck/manifest"
+	"golang.org/x/xerrors"
+)
+
+// literalConstants constants printed by name instead of by value.
+var literalConstants = map[any]string{
+	manifest.Keypad:     "manifest.Keypad",
+	manifest.Encoder:    "manifest.Encoder",
+	manifest.Mac:        "manifest.Mac",
+	manifest.Windows:    "manifest.Windows",
+	streamdeck.LayoutB1: "streamdeck.LayoutB1",
+}
+
+// goLiteral formats v as a Go composite literal, leaving out zero fields.
+func goLiteral(v any) (string, error) {
+	var b bytes.Buffer
+	writeLiteral(&b, reflect.ValueOf(v), true)
+	src, err := format.Source([]byte("package p\nvar v = " + b.String()))
+	if err != nil {
+		return "", xerrors.Errorf("failed to format literal of %T: %w", v, err)
+	}
+	_, lit, _ := strings.Cut(string(src), "var v = ")
+	return strings.TrimSpace(lit), nil
+}
+
+// writeLiteral writes the literal of v. typed is false for elements of slices, whose type is elided.
+func writeLiteral(b *bytes.Buffer, v reflect.Value, typed bool) {
+	if v.Kind() == reflect.String {
+		if name, ok := literalConstants[v.Interface()]; ok {
+			b.WriteString(name)
+			return
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if typed {
+			b.WriteString("&")
+		}
+		writeLiteral(b, v.Elem(), typed)
+	case reflect.Struct:
+		if typed {
+			b.WriteString(typeName(v.Type()))
+		}
+		b.WriteString("{\n")
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Field(i)
+			if !v.Type().Field(i).IsExported() || f.IsZero() {
+				continue
+			}
+			b.WriteString(v.Type().Field(i).Name + ": ")
+			writeLiteral(b, f, true)
+			b.WriteString(",\n")
+		}
+		b.WriteString("}")
+	case reflect.Slice:
+		b.WriteString(typeName(v.Type()) + "{")
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			writeLiteral(b, v.Index(i), false)
+		}
+		b.WriteString("}")
+	case reflect.String:
+		s := strconv.Quote(v.String())
+		if v.Type().PkgPath() != "" {
+			s = typeName(v.Type()) + "(" + s + ")"
+		}
+		b.WriteString(s)
+	default:
+		fmt.Fprintf(b, "%#v", v.Interface())
+	}
+}
+
+// typeName name of t qualified by the name of its package, as imported by the generated code.
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return "*" + typeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + typeName(t.Elem())
+	}
+	if t.PkgPath() == "" {
+		return t.Name()
+	}
+	return t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:] + "." + t.Name()
+}
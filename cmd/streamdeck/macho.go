@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"debug/macho"
+	"encoding/binary"
+	"os"
+
+	"golang.org/x/xerrors"
+)
+
+// fatAlign alignment of the architectures in a universal binary, 2^14 like lipo does for arm64.
+const fatAlign = 14
+
+// writeUniversal combines thin Mach-O binaries into a universal (fat) binary at out, as lipo -create does.
+func writeUniversal(out string, thin ...string) error {
+	var images [][]byte
+	var arches []macho.FatArchHeader
+	for _, p := range thin {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		f, err := macho.NewFile(bytes.NewReader(b))
+		if err != nil {
+			return xerrors.Errorf("%s is not a Mach-O binary: %w", p, err)
+		}
+		images = append(images, b)
+		arches = append(arches, macho.FatArchHeader{
+			Cpu:    f.Cpu,
+			SubCpu: f.SubCpu,
+			Size:   uint32(len(b)),
+			Align:  fatAlign,
+		})
+	}
+
+	// fat header: magic and number of architectures, followed by a header per architecture, all big endian
+	const headerSize, archSize = 8, 20
+	offset := uint32(headerSize + archSize*len(arches))
+	for i := range arches {
+		offset = alignUp(offset, 1<<fatAlign)
+		arches[i].Offset = offset
+		offset += arches[i].Size
+	}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, []uint32{macho.MagicFat, uint32(len(arches))})
+	for _, a := range arches {
+		binary.Write(&buf, binary.BigEndian, []uint32{uint32(a.Cpu), a.SubCpu, a.Offset, a.Size, a.Align})
+	}
+	for i, a := range arches {
+		buf.Write(make([]byte, int(a.Offset)-buf.Len()))
+		buf.Write(images[i])
+	}
+
+	if err := os.WriteFile(out, buf.Bytes(), 0o755); err != nil {
+		return xerrors.Errorf("failed to write universal binary: %w", err)
+	}
+	return nil
+}
+
+func alignUp(n, align uint32) uint32 {
+	return (n + align - 1) &^ (align - 1)
+}
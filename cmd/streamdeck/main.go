@@ -0,0 +1,65 @@
+// Command streamdeck creates, builds, packages, validates and runs Stream Deck plugins written with this SDK.
+//
+//	streamdeck new [flags] <uuid>  create a plugin project from a template
+//	streamdeck build [flags]       cross-compile the plugin into the .sdPlugin directory
+//	streamdeck pack [flags]        build, then zip the .sdPlugin directory into a .streamDeckPlugin file
+//	streamdeck validate [flags]    validate the manifest and the files it refers to
+//	streamdeck dev [flags]         run the plugin against a fake Stream Deck software, restarting it on changes
+//
+// All commands run on Linux, macOS and Windows: binaries are cross-compiled with CGO disabled,
+// and the macOS universal binary is assembled without lipo.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{name: "new", usage: "create a plugin project from a template", run: runNew},
+	{name: "build", usage: "cross-compile the plugin into the .sdPlugin directory", run: runBuild},
+	{name: "pack", usage: "build, then zip the .sdPlugin directory into a .streamDeckPlugin file", run: runPack},
+	{name: "validate", usage: "validate the manifest and the files it refers to", run: runValidate},
+	{name: "dev", usage: "run the plugin against a fake Stream Deck software, restarting it on changes", run: runDev},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: streamdeck <command> [flags]\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.usage)
+	}
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("streamdeck: ")
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	for _, c := range commands {
+		if c.name != os.Args[1] {
+			continue
+		}
+		if err := c.run(os.Args[2:]); err != nil {
+			// the usage was printed by the flag set
+			if errors.Is(err, flag.ErrHelp) {
+				return
+			}
+			log.Fatal(err)
+		}
+		return
+	}
+	usage()
+	os.Exit(2)
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"debug/macho"
+	"encoding/binary"
+	"errors"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/FlowingSPDG/streamdeck/manifest"
+	"github.com/FlowingSPDG/streamdeck/sdtest"
+)
+
+// thinMachO returns the header of an empty 64-bit Mach-O executable.
+func thinMachO(cpu macho.Cpu, subCpu uint32) []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, []uint32{macho.Magic64, uint32(cpu), subCpu, uint32(macho.TypeExec), 0, 0, 0, 0})
+	return b.Bytes()
+}
+
+func TestWriteUniversal(t *testing.T) {
+	dir := t.TempDir()
+	amd64 := filepath.Join(dir, "amd64")
+	arm64 := filepath.Join(dir, "arm64")
+	if err := os.WriteFile(amd64, thinMachO(macho.CpuAmd64, 3), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(arm64, thinMachO(macho.CpuArm64, 0), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "universal")
+	if err := writeUniversal(out, amd64, arm64); err != nil {
+		t.Fatal(err)
+	}
+	f, err := macho.OpenFat(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if len(f.Arches) != 2 {
+		t.Fatalf("%d architectures, want 2", len(f.Arches))
+	}
+	for i, cpu := range []macho.Cpu{macho.CpuAmd64, macho.CpuArm64} {
+		a := f.Arches[i]
+		if a.Cpu != cpu || a.Offset%(1<<fatAlign) != 0 || a.File == nil {
+			t.Errorf("arch %d = %+v, want %v aligned", i, a.FatArchHeader, cpu)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	o := buildOptions{dir: "plugin"}
+	m := loadTestManifest(t)
+	if err := o.resolve(m); err != nil {
+		t.Fatal(err)
+	}
+	if o.uuid != "dev.samwho.streamdeck.counter" || o.name != "counter" || o.out != filepath.Join("plugin", "dev.samwho.streamdeck.counter.sdPlugin") {
+		t.Errorf("resolve() = %+v", o)
+	}
+}
+
+func TestPackAssets(t *testing.T) {
+	src := t.TempDir()
+	for name, content := range map[string]string{
+		"main.go":                         "package main",
+		"go.mod":                          "module x",
+		"manifest.json":                   "{}",
+		"README.md":                       "",
+		"images/icon.png":                 "png",
+		"pi/index.html":                   "html",
+		".git/config":                     "",
+		"testdata/golden.png":             "",
+		"x.sdPlugin/manifest.json":        "{}",
+		"layouts/volume.json":             "{}",
+		"profiles/Main.streamDeckProfile": "profile",
+	} {
+		p := filepath.Join(src, name)
+		os.MkdirAll(filepath.Dir(p), 0o755)
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := filepath.Join(t.TempDir(), "x.sdPlugin")
+	if err := copyAssets(src, out); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(out, "x"), []byte("binary"), 0o644)
+
+	output := filepath.Join(t.TempDir(), "x.streamDeckPlugin")
+	if err := zipPlugin(out, output, "x"); err != nil {
+		t.Fatal(err)
+	}
+	r, err := zip.OpenReader(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	got := map[string]os.FileMode{}
+	for _, f := range r.File {
+		got[f.Name] = f.Mode()
+	}
+	want := []string{"x.sdPlugin/images/icon.png", "x.sdPlugin/pi/index.html", "x.sdPlugin/layouts/volume.json", "x.sdPlugin/profiles/Main.streamDeckProfile", "x.sdPlugin/x"}
+	if len(got) != len(want) {
+		t.Errorf("archive = %v, want %v", got, want)
+	}
+	for _, name := range want {
+		if _, ok := got[name]; !ok {
+			t.Errorf("archive is missing %s: %v", name, got)
+		}
+	}
+	if got["x.sdPlugin/x"]&0o111 == 0 {
+		t.Errorf("mac binary mode = %v, want executable", got["x.sdPlugin/x"])
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, tmpl := range projectTemplates {
+		t.Run(tmpl.name, func(t *testing.T) {
+			p, err := newProject(&tmpl, "com.example.my-plugin", "", "", "tester")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Name != "My Plugin" || p.Binary != "my-plugin" || p.ActionUUID != "com.example.my-plugin.action" {
+				t.Errorf("project = %q %q %q", p.Name, p.Binary, p.ActionUUID)
+			}
+
+			dir := t.TempDir()
+			if err := p.write(&tmpl, dir); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range []string{"main.go", "main_test.go"} {
+				if _, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, name), nil, 0); err != nil {
+					t.Error(err)
+				}
+			}
+			if err := validate(dir); err != nil {
+				t.Error(err)
+			}
+			if err := p.write(&tmpl, dir); err == nil {
+				t.Error("write to a non-empty directory succeeded")
+			}
+		})
+	}
+
+	for _, uuid := range []string{"MyPlugin", "com.example.-foo", "com.example.a--b", "com.example.foo-"} {
+		if _, err := newProject(&projectTemplates[0], uuid, "", "", ""); !errors.Is(err, manifest.ErrInvalidUUID) {
+			t.Errorf("newProject(%q) err = %v, want ErrInvalidUUID", uuid, err)
+		}
+	}
+}
+
+func TestPlaceActions(t *testing.T) {
+	m, err := manifest.Parse([]byte(`{"Actions": [
+		{"UUID": "com.example.key", "States": [{}, {}]},
+		{"UUID": "com.example.dial", "Controllers": ["Encoder"]},
+		{"UUID": "com.example.other"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deck := sdtest.NewDeck(sdtest.DefaultInfo())
+	placed := placeActions(deck, m)
+	if len(placed) != 3 {
+		t.Fatalf("placed %d actions, want 3", len(placed))
+	}
+
+	want := []struct {
+		device, controller string
+		column, states     int
+	}{
+		{sdtest.DeviceID, sdtest.Keypad, 0, 2},
+		{sdtest.PlusDeviceID, sdtest.Encoder, 0, 1},
+		{sdtest.DeviceID, sdtest.Keypad, 1, 1},
+	}
+	for i, w := range want {
+		k, _ := deck.Key(placed[i].Context)
+		if k.Device != w.device || k.Controller != w.controller || k.Coordinates.Column != w.column || k.States != w.states {
+			t.Errorf("%s placed on %s %s %d with %d states, want %+v", k.Action, k.Device, k.Controller, k.Coordinates.Column, k.States, w)
+		}
+	}
+}
+
+func TestSourceModTimes(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"main.go", "go.mod", "images/icon.png", "plugin.sdPlugin/main.go"} {
+		if err := writeFile(filepath.Join(dir, name), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before, err := sourceModTimes(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(before) != 2 {
+		t.Errorf("sources = %v, want main.go and go.mod", before)
+	}
+
+	if err := os.Chtimes(filepath.Join(dir, "images/icon.png"), time.Time{}, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := sourceModTimes(dir)
+	if !sameModTimes(before, after) {
+		t.Error("change of an asset reported as a change of the sources")
+	}
+	if err := os.Chtimes(filepath.Join(dir, "main.go"), time.Time{}, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	after, _ = sourceModTimes(dir)
+	if sameModTimes(before, after) {
+		t.Error("change of main.go not reported")
+	}
+}
+
+func loadTestManifest(t *testing.T) *manifest.Manifest {
+	t.Helper()
+	m, err := manifest.Parse([]byte(`{"Actions": [{"UUID": "dev.samwho.streamdeck.counter"}], "CodePath": "counter.exe"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
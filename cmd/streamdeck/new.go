@@ -0,0 +1,347 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"flag"
+	"go/format"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"text/template"
+
+	"github.com/FlowingSPDG/streamdeck"
+	"github.com/FlowingSPDG/streamdeck/manifest"
+	"golang.org/x/xerrors"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// sdkModule module path of the SDK, required by the generated go.mod.
+const sdkModule = "github.com/FlowingSPDG/streamdeck"
+
+// uuidPattern reverse-DNS identifier whose segments are words separated by single hyphens, the last one names the binary.
+var uuidPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*(\.[a-z0-9]+(-[a-z0-9]+)*)+$`)
+
+// projectTemplate kind of action a new project starts with.
+type projectTemplate struct {
+	name  string
+	usage string
+	// action spec of the action, ActionUUID and Name are set from the plugin.
+	action streamdeck.ActionSpec
+	// images images generated in the project, without extension, and their color.
+	images map[string]color.RGBA
+	// piItems property inspector items editing the settings.
+	piItems string
+}
+
+var (
+	iconColor = color.RGBA{R: 0x00, G: 0x78, B: 0xff, A: 0xff}
+	keyColor  = color.RGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xff}
+	onColor   = color.RGBA{R: 0x2e, G: 0xa0, B: 0x43, A: 0xff}
+)
+
+var projectTemplates = []projectTemplate{
+	{
+		name:  "keypad",
+		usage: "key counting presses",
+		action: streamdeck.ActionSpec{
+			Icon:                    "images/action",
+			Tooltip:                 "Count key presses",
+			States:                  []manifest.State{{Image: "images/key", TitleAlignment: "middle"}},
+			PropertyInspectorPath:   "property_inspector.html",
+			SupportedInMultiActions: true,
+		},
+		images: map[string]color.RGBA{"images/action": iconColor, "images/key": keyColor},
+		piItems: `    <div class="sdpi-item">
+      <div class="sdpi-item-label">Count</div>
+      <input class="sdpi-item-value" type="number" data-setting="count">
+    </div>`,
+	},
+	{
+		name:  "encoder",
+		usage: "Stream Deck + dial changing a value",
+		action: streamdeck.ActionSpec{
+			Icon:        "images/action",
+			Tooltip:     "Change a value with the dial",
+			States:      []manifest.State{{Image: "images/key"}},
+			Controllers: []manifest.Controller{manifest.Encoder},
+			Encoder: &manifest.ActionEncoder{
+				Layout: streamdeck.LayoutB1,
+				TriggerDescription: &manifest.TriggerDescription{
+					Rotate: "Change value",
+					Push:   "Reset",
+				},
+			},
+			PropertyInspectorPath: "property_inspector.html",
+		},
+		images: map[string]color.RGBA{"images/action": iconColor, "images/key": keyColor},
+		piItems: `    <div class="sdpi-item">
+      <div class="sdpi-item-label">Value</div>
+      <input class="sdpi-item-value" type="range" min="0" max="100" data-setting="value">
+    </div>`,
+	},
+	{
+		name:  "multistate",
+		usage: "key toggling between two states",
+		action: streamdeck.ActionSpec{
+			Icon:    "images/action",
+			Tooltip: "Toggle between off and on",
+			States: []manifest.State{
+				{Name: "Off", Image: "images/off"},
+				{Name: "On", Image: "images/on"},
+			},
+			PropertyInspectorPath:   "property_inspector.html",
+			SupportedInMultiActions: true,
+		},
+		images: map[string]color.RGBA{"images/action": iconColor, "images/off": keyColor, "images/on": onColor},
+		piItems: `    <div type="checkbox" class="sdpi-item">
+      <div class="sdpi-item-label">On</div>
+      <div class="sdpi-item-child">
+        <input id="on" type="checkbox" data-setting="on">
+        <label for="on" class="sdpi-item-label"><span></span></label>
+      </div>
+    </div>`,
+	},
+}
+
+// project values of a new project, used by the templates.
+type project struct {
+	UUID       string
+	ActionUUID string
+	Name       string
+	Module     string
+	Binary     string
+	SDKVersion string
+	Replace    string
+	// Plugin, Action Go literals of the manifest metadata and the action spec
+	Plugin string
+	Action string
+	// PropertyInspectorItems HTML of the property inspector items
+	PropertyInspectorItems string
+
+	manifest manifest.Manifest
+	spec     streamdeck.ActionSpec
+}
+
+func runNew(args []string) error {
+	fs := flag.NewFlagSet("new", flag.ContinueOnError)
+	fs.Usage = func() {
+		fs.Output().Write([]byte("usage: streamdeck new [flags] <plugin uuid>\n\ntemplates:\n"))
+		for _, t := range projectTemplates {
+			fs.Output().Write([]byte("  " + t.name + ": " + t.usage + "\n"))
+		}
+		fs.Output().Write([]byte("\nflags:\n"))
+		fs.PrintDefaults()
+	}
+	tmpl := fs.String("template", "keypad", "template of the action: keypad, encoder or multistate")
+	dir := fs.String("dir", "", "directory of the project. the last part of the UUID by default")
+	name := fs.String("name", "", "display name of the plugin. derived from the UUID by default")
+	module := fs.String("module", "", "Go module path. the last part of the UUID by default")
+	author := fs.String("author", "", "author of the plugin. the current user by default")
+	replace := fs.String("replace", "", "local directory of the SDK, added as a replace directive to go.mod")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return xerrors.New("new takes the plugin UUID as its only argument")
+	}
+
+	var t *projectTemplate
+	for i := range projectTemplates {
+		if projectTemplates[i].name == *tmpl {
+			t = &projectTemplates[i]
+		}
+	}
+	if t == nil {
+		return xerrors.Errorf("unknown template %q", *tmpl)
+	}
+
+	p, err := newProject(t, fs.Arg(0), *name, *module, *author)
+	if err != nil {
+		return err
+	}
+	p.Replace = *replace
+	if *dir == "" {
+		*dir = p.Binary
+	}
+	if err := p.write(t, *dir); err != nil {
+		return err
+	}
+	log.Printf("created %s in %s. run go mod tidy and go test ./... in it to get started", p.UUID, *dir)
+	return nil
+}
+
+func newProject(t *projectTemplate, uuid, name, module, author string) (*project, error) {
+	if !uuidPattern.MatchString(uuid) {
+		return nil, xerrors.Errorf("%w: %q must be a reverse-DNS identifier such as com.example.myplugin", manifest.ErrInvalidUUID, uuid)
+	}
+	last := uuid[strings.LastIndex(uuid, ".")+1:]
+	if name == "" {
+		words := strings.Split(last, "-")
+		for i, w := range words {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+		name = strings.Join(words, " ")
+	}
+	if module == "" {
+		module = last
+	}
+	if author == "" {
+		author = "unknown"
+		if u, err := user.Current(); err == nil {
+			author = u.Username
+		}
+	}
+
+	p := &project{
+		UUID:                   uuid,
+		ActionUUID:             uuid + ".action",
+		Name:                   name,
+		Module:                 module,
+		Binary:                 last,
+		SDKVersion:             sdkVersion(),
+		PropertyInspectorItems: t.piItems,
+	}
+	p.manifest = manifest.Manifest{
+		Author:      author,
+		CodePath:    p.Binary,
+		Description: name,
+		Icon:        "images/plugin",
+		Name:        name,
+		OS: []manifest.OS{
+			{Platform: manifest.Mac, MinimumVersion: "12"},
+			{Platform: manifest.Windows, MinimumVersion: "10"},
+		},
+		Software: manifest.Software{MinimumVersion: "6.4"},
+		UUID:     uuid,
+		Version:  "0.1.0",
+	}
+	p.spec = t.action
+	p.spec.UUID = p.ActionUUID
+	p.spec.Name = name
+
+	var err error
+	if p.Plugin, err = goLiteral(p.manifest); err != nil {
+		return nil, err
+	}
+	if p.Action, err = goLiteral(p.spec); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// sdkVersion version of the SDK the command was built with, empty for development builds.
+func sdkVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	// versions of builds with local changes, such as v0.0.0-...+dirty, can not be required
+	if info.Main.Path == sdkModule && strings.HasPrefix(info.Main.Version, "v") && !strings.Contains(info.Main.Version, "+") {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == sdkModule {
+			return dep.Version
+		}
+	}
+	return ""
+}
+
+func (p *project) write(t *projectTemplate, dir string) error {
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return xerrors.Errorf("%s already exists and is not empty", dir)
+	}
+
+	files := map[string]string{
+		"go.mod":                  "templates/common/go.mod.tmpl",
+		"property_inspector.html": "templates/common/property_inspector.html.tmpl",
+		"main.go":                 "templates/" + t.name + "/main.go.tmpl",
+		"main_test.go":            "templates/" + t.name + "/main_test.go.tmpl",
+	}
+	for name, tmpl := range files {
+		b, err := p.execute(tmpl)
+		if err != nil {
+			return err
+		}
+		if filepath.Ext(name) == ".go" {
+			if b, err = format.Source(b); err != nil {
+				return xerrors.Errorf("failed to format %s: %w", name, err)
+			}
+		}
+		if err := writeFile(filepath.Join(dir, name), b); err != nil {
+			return err
+		}
+	}
+
+	css, err := templates.ReadFile("templates/common/sdpi.css")
+	if err != nil {
+		return err
+	}
+	if err := writeFile(filepath.Join(dir, "sdpi.css"), css); err != nil {
+		return err
+	}
+
+	images := map[string]color.RGBA{"images/plugin": iconColor}
+	for name, c := range t.images {
+		images[name] = c
+	}
+	for name, c := range images {
+		if err := writeIcon(filepath.Join(dir, filepath.FromSlash(name)), c); err != nil {
+			return err
+		}
+	}
+
+	// the manifest is generated like go generate does, from the action spec of main.go
+	client := streamdeck.NewClient(context.Background(), streamdeck.RegistrationParams{})
+	client.RegisterAction(p.spec)
+	return client.WriteManifest(filepath.Join(dir, manifest.FileName), p.manifest)
+}
+
+func (p *project) execute(name string) ([]byte, error) {
+	tmpl, err := template.ParseFS(templates, name)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, p); err != nil {
+		return nil, xerrors.Errorf("failed to execute %s: %w", name, err)
+	}
+	return b.Bytes(), nil
+}
+
+func writeFile(path string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+// writeIcon writes a placeholder image of the color at path, with .png and @2x.png extensions.
+// Icons of the actions list are 20x20, keys 72x72: 72x72 is used for both, the Stream Deck software scales them.
+func writeIcon(path string, c color.RGBA) error {
+	for ext, size := range map[string]int{".png": 72, "@2x.png": 144} {
+		img := image.NewRGBA(image.Rect(0, 0, size, size))
+		draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+		var b bytes.Buffer
+		if err := png.Encode(&b, img); err != nil {
+			return err
+		}
+		if err := writeFile(path+ext, b.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"archive/zip"
+	"flag"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/FlowingSPDG/streamdeck/manifest"
+	"golang.org/x/xerrors"
+)
+
+func runPack(args []string) error {
+	fs := flag.NewFlagSet("pack", flag.ContinueOnError)
+	var o buildOptions
+	o.flags(fs)
+	output := fs.String("o", "", "output .streamDeckPlugin file. <uuid>.streamDeckPlugin in -dir by default")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	m, err := loadManifest(&o)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		*output = filepath.Join(o.dir, o.uuid+".streamDeckPlugin")
+	}
+
+	// files of earlier builds would be shipped, only a .sdPlugin directory is removed
+	if filepath.Ext(o.out) != ".sdPlugin" {
+		return xerrors.Errorf("output directory %s must end with .sdPlugin", o.out)
+	}
+	if err := os.RemoveAll(o.out); err != nil {
+		return xerrors.Errorf("failed to clean %s: %w", o.out, err)
+	}
+	if err := build(o, m); err != nil {
+		return err
+	}
+	if err := copyAssets(o.dir, o.out); err != nil {
+		return err
+	}
+	if err := validate(o.out); err != nil {
+		return err
+	}
+	if err := zipPlugin(o.out, *output, m.CodePathMac); err != nil {
+		return err
+	}
+	log.Printf("packed %s", *output)
+	return nil
+}
+
+// skipAsset reports whether the file or directory of the plugin source is left out of the .sdPlugin directory.
+func skipAsset(name string, dir bool) bool {
+	if strings.HasPrefix(name, ".") {
+		return true
+	}
+	if dir {
+		return name == "testdata" || name == "vendor" || filepath.Ext(name) == ".sdPlugin"
+	}
+	switch name {
+	case "go.mod", "go.sum", "Makefile", manifest.FileName:
+		return true
+	}
+	switch filepath.Ext(name) {
+	case ".go", ".md", ".streamDeckPlugin":
+		return true
+	}
+	return false
+}
+
+// copyAssets copies the files of the plugin source (images, property inspectors, layouts, profiles...) into the .sdPlugin directory.
+// Go sources, module files and the manifest, written by build, are left out.
+func copyAssets(src, out string) error {
+	absOut, err := filepath.Abs(out)
+	if err != nil {
+		return err
+	}
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == src {
+			return nil
+		}
+		if abs, _ := filepath.Abs(p); abs == absOut {
+			return filepath.SkipDir
+		}
+		if skipAsset(d.Name(), d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		return copyFile(p, filepath.Join(out, rel))
+	})
+}
+
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return xerrors.Errorf("failed to copy %s: %w", src, err)
+	}
+	return out.Close()
+}
+
+// zipPlugin zips the .sdPlugin directory into a .streamDeckPlugin file, with the directory at the root of the archive.
+// The macOS binary is marked executable whatever the file mode on the host.
+func zipPlugin(dir, output, macBinary string) error {
+	f, err := os.Create(output)
+	if err != nil {
+		return xerrors.Errorf("failed to create %s: %w", output, err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	root := filepath.Base(dir)
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		h, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		h.Name = root + "/" + filepath.ToSlash(rel)
+		h.Method = zip.Deflate
+		if macBinary != "" && filepath.ToSlash(rel) == macBinary {
+			h.SetMode(0o755)
+		}
+
+		w, err := zw.CreateHeader(h)
+		if err != nil {
+			return err
+		}
+		in, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		_, err = io.Copy(w, in)
+		return err
+	})
+	if err != nil {
+		zw.Close()
+		return xerrors.Errorf("failed to zip %s: %w", dir, err)
+	}
+	if err := zw.Close(); err != nil {
+		return xerrors.Errorf("failed to zip %s: %w", dir, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/FlowingSPDG/streamdeck/manifest"
+)
+
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	dir := fs.String("dir", ".", "directory of the manifest.json, the plugin source or the .sdPlugin directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := validate(*dir); err != nil {
+		return err
+	}
+	log.Printf("%s is valid", filepath.Join(*dir, manifest.FileName))
+	return nil
+}
+
+// validate validates the manifest of the directory and the files it refers to.
+func validate(dir string) error {
+	m, err := manifest.Load(filepath.Join(dir, manifest.FileName))
+	if err != nil {
+		return err
+	}
+	return m.Validate(os.DirFS(dir))
+}
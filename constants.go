@@ -1,5 +1,13 @@
 package streamdeck
 
+// Registration events, passed to the plugin with -registerEvent and to property inspectors with inRegisterEvent.
+const (
+	// RegisterPlugin Event a plugin registers with.
+	RegisterPlugin = "registerPlugin"
+	// RegisterPropertyInspector Event a property inspector registers with.
+	RegisterPropertyInspector = "registerPropertyInspector"
+)
+
 const (
 	// DidReceiveSettings Event received after calling the getSettings API to retrieve the persistent data stored for the action.
 	DidReceiveSettings = "didReceiveSettings"
@@ -10,6 +10,7 @@ const (
 	contextKey keyType = iota
 	deviceKey
 	actionKey
+	multiActionKey
 )
 
 func Context(ctx context.Context) string {
@@ -36,6 +37,18 @@ func WithAction(ctx context.Context, streamdeckAction string) context.Context {
 	return context.WithValue(ctx, actionKey, streamdeckAction)
 }
 
+func IsInMultiAction(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	v, _ := ctx.Value(multiActionKey).(bool)
+	return v
+}
+
+func WithMultiAction(ctx context.Context, inMultiAction bool) context.Context {
+	return context.WithValue(ctx, multiActionKey, inMultiAction)
+}
+
 func get(ctx context.Context, key keyType) string {
 	if ctx == nil {
 		return ""
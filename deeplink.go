@@ -0,0 +1,309 @@
+package streamdeck
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// DeepLink A deep link received with didReceiveDeepLink, matched by a DeepLinkRouter.
+type DeepLink struct {
+	// URL the deep link without the streamdeck://plugins/message/<uuid> prefix, e.g. /toggle/mic?on=true.
+	URL *url.URL
+	// Pattern pattern of the route that matched.
+	Pattern string
+	params  map[string]string
+}
+
+// Param Get the path segment matched by {name} in the pattern, empty if there is none.
+func (l *DeepLink) Param(name string) string {
+	return l.params[name]
+}
+
+// Query Get the query parameters of the deep link.
+func (l *DeepLink) Query() url.Values {
+	return l.URL.Query()
+}
+
+// DeepLinkHandler Handle a deep link.
+type DeepLinkHandler func(ctx context.Context, client *Client, link *DeepLink) error
+
+// DeepLinkMiddleware Wrap the handler of every route, e.g. to log or authorize deep links.
+type DeepLinkMiddleware func(next DeepLinkHandler) DeepLinkHandler
+
+// DeepLinkRouter Routes the deep links of the plugin, streamdeck://plugins/message/<uuid>/<path>, to handlers by their path.
+type DeepLinkRouter struct {
+	mu         sync.RWMutex
+	routes     []deepLinkRoute
+	middleware []DeepLinkMiddleware
+	notFound   DeepLinkHandler
+}
+
+type deepLinkRoute struct {
+	pattern  string
+	segments []string
+	handler  DeepLinkHandler
+}
+
+// NewDeepLinkRouter Get a new router without routes.
+func NewDeepLinkRouter() *DeepLinkRouter {
+	return &DeepLinkRouter{}
+}
+
+// Handle Register the handler of the path pattern. Segments of the pattern are literal, {name} matching any one segment,
+// or a final {name...} matching the rest of the path. Routes are tried in the order they were registered.
+// Handle panics if the pattern is malformed, like http.ServeMux.
+func (r *DeepLinkRouter) Handle(pattern string, handler DeepLinkHandler) {
+	segments := splitPath(pattern)
+	for i, s := range segments {
+		name, wildcard := pathParam(s)
+		if wildcard && strings.HasSuffix(name, "...") && i != len(segments)-1 {
+			panic("streamdeck: {" + name + "} must be the last segment of deep link pattern " + pattern)
+		}
+		if !wildcard && strings.ContainsAny(s, "{}") {
+			panic("streamdeck: malformed deep link pattern " + pattern)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes = append(r.routes, deepLinkRoute{pattern: pattern, segments: segments, handler: handler})
+}
+
+// HandleDeepLink Register a handler of the path pattern receiving the query of the deep link bound to T. See BindQuery.
+func HandleDeepLink[T any](r *DeepLinkRouter, pattern string, handler func(ctx context.Context, client *Client, link *DeepLink, query T) error) {
+	r.Handle(pattern, func(ctx context.Context, client *Client, link *DeepLink) error {
+		var q T
+		if err := BindQuery(link.Query(), &q); err != nil {
+			return err
+		}
+		return handler(ctx, client, link, q)
+	})
+}
+
+// Use Add middleware wrapping every route, including routes registered before. The first middleware added is the outermost.
+func (r *DeepLinkRouter) Use(middleware ...DeepLinkMiddleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// NotFound Set the handler of deep links no route matches. Dispatch returns ErrDeepLinkNotFound for them when it is not set.
+func (r *DeepLinkRouter) NotFound(handler DeepLinkHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notFound = handler
+}
+
+// Dispatch Call the handler of the first route matching the deep link, through the middleware.
+// rawURL is the url of didReceiveDeepLink, with or without the streamdeck://plugins/message/<uuid> prefix.
+func (r *DeepLinkRouter) Dispatch(ctx context.Context, client *Client, rawURL string) error {
+	u, err := parseDeepLink(rawURL)
+	if err != nil {
+		return err
+	}
+	link := &DeepLink{URL: u}
+
+	r.mu.RLock()
+	handler := r.notFound
+	for _, route := range r.routes {
+		if params, ok := route.match(splitPath(u.EscapedPath())); ok {
+			link.Pattern, link.params, handler = route.pattern, params, route.handler
+			break
+		}
+	}
+	middleware := r.middleware
+	r.mu.RUnlock()
+
+	if handler == nil {
+		handler = func(ctx context.Context, client *Client, link *DeepLink) error {
+			return xerrors.Errorf("%w: %s", ErrDeepLinkNotFound, link.URL.Path)
+		}
+	}
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler(ctx, client, link)
+}
+
+// MountDeepLinks Dispatch the didReceiveDeepLink events of the client to the router.
+func (client *Client) MountDeepLinks(r *DeepLinkRouter) {
+	client.RegisterNoActionHandler(DidReceiveDeepLink, func(ctx context.Context, client *Client, event Event) error {
+		var p DidReceiveDeepLinkPayload
+		if err := event.UnmarshalPayload(&p); err != nil {
+			return xerrors.Errorf("failed to unmarshal %s payload: %w", DidReceiveDeepLink, err)
+		}
+		return r.Dispatch(ctx, client, p.URL)
+	})
+}
+
+func (route deepLinkRoute) match(path []string) (map[string]string, bool) {
+	params := map[string]string{}
+	for i, s := range route.segments {
+		name, wildcard := pathParam(s)
+		if wildcard && strings.HasSuffix(name, "...") {
+			rest := make([]string, 0, len(path)-i)
+			for _, p := range path[min(i, len(path)):] {
+				rest = append(rest, unescapeSegment(p))
+			}
+			params[strings.TrimSuffix(name, "...")] = strings.Join(rest, "/")
+			return params, true
+		}
+		if i >= len(path) {
+			return nil, false
+		}
+		if wildcard {
+			params[name] = unescapeSegment(path[i])
+		} else if s != unescapeSegment(path[i]) {
+			return nil, false
+		}
+	}
+	return params, len(path) == len(route.segments)
+}
+
+// parseDeepLink parses the url of a deep link and strips the prefix of the plugin.
+func parseDeepLink(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, xerrors.Errorf("%w: %v", ErrInvalidDeepLink, err)
+	}
+	if u.Scheme != "" {
+		// streamdeck://plugins/message/<uuid>/path
+		segments := splitPath(u.Host + u.EscapedPath())
+		if len(segments) < 3 || segments[0] != "plugins" || segments[1] != "message" {
+			return nil, xerrors.Errorf("%w: %s", ErrInvalidDeepLink, rawURL)
+		}
+		u = &url.URL{RawQuery: u.RawQuery, Fragment: u.Fragment}
+		if err := setPath(u, "/"+strings.Join(segments[3:], "/")); err != nil {
+			return nil, err
+		}
+	}
+	return u, nil
+}
+
+func setPath(u *url.URL, escaped string) error {
+	p, err := url.PathUnescape(escaped)
+	if err != nil {
+		return xerrors.Errorf("%w: %v", ErrInvalidDeepLink, err)
+	}
+	u.Path, u.RawPath = p, escaped
+	return nil
+}
+
+func splitPath(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+// pathParam gets the name of a {name} segment.
+func pathParam(segment string) (string, bool) {
+	if len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}' {
+		return segment[1 : len(segment)-1], true
+	}
+	return "", false
+}
+
+func unescapeSegment(s string) string {
+	if u, err := url.PathUnescape(s); err == nil {
+		return u
+	}
+	return s
+}
+
+// BindQuery Set the fields of the struct v points to from the query parameters. Fields are named by their query tag,
+// or by their json tag, or by their name; query:"-" skips a field. Strings, booleans, numbers and slices of them are supported.
+func BindQuery(query url.Values, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return xerrors.Errorf("%w: query must be bound to a pointer to a struct, got %T", ErrInvalidDeepLink, v)
+	}
+	rv = rv.Elem()
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := queryName(sf)
+		if name == "-" {
+			continue
+		}
+		values, ok := query[name]
+		if !ok || len(values) == 0 {
+			continue
+		}
+
+		f := rv.Field(i)
+		if f.Kind() == reflect.Slice {
+			s := reflect.MakeSlice(f.Type(), len(values), len(values))
+			for j, value := range values {
+				if err := setQueryValue(s.Index(j), value); err != nil {
+					return xerrors.Errorf("%w: %s: %v", ErrInvalidDeepLink, name, err)
+				}
+			}
+			f.Set(s)
+			continue
+		}
+		if err := setQueryValue(f, values[0]); err != nil {
+			return xerrors.Errorf("%w: %s: %v", ErrInvalidDeepLink, name, err)
+		}
+	}
+	return nil
+}
+
+func queryName(sf reflect.StructField) string {
+	if tag, ok := sf.Tag.Lookup("query"); ok {
+		return tag
+	}
+	if name, _, _ := strings.Cut(sf.Tag.Get("json"), ","); name != "" {
+		return name
+	}
+	return sf.Name
+}
+
+func setQueryValue(v reflect.Value, s string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		// a parameter without value, e.g. ?mute, is true
+		if s == "" {
+			v.SetBool(true)
+			return nil
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return xerrors.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
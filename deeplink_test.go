@@ -0,0 +1,105 @@
+package streamdeck_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/FlowingSPDG/streamdeck"
+	"github.com/FlowingSPDG/streamdeck/sdtest"
+)
+
+func TestDeepLinkRouter(t *testing.T) {
+	r := streamdeck.NewDeepLinkRouter()
+	var got []string
+	route := func(name string) streamdeck.DeepLinkHandler {
+		return func(ctx context.Context, client *streamdeck.Client, link *streamdeck.DeepLink) error {
+			got = append(got, name+" "+link.Param("id")+link.Param("path"))
+			return nil
+		}
+	}
+	r.Handle("/toggle/all", route("all"))
+	r.Handle("/toggle/{id}", route("toggle"))
+	r.Handle("/files/{path...}", route("files"))
+	r.Use(func(next streamdeck.DeepLinkHandler) streamdeck.DeepLinkHandler {
+		return func(ctx context.Context, client *streamdeck.Client, link *streamdeck.DeepLink) error {
+			got = append(got, "mw "+link.Pattern)
+			return next(ctx, client, link)
+		}
+	})
+
+	tests := []struct {
+		url  string
+		want []string
+	}{
+		{url: "/toggle/mic", want: []string{"mw /toggle/{id}", "toggle mic"}},
+		{url: "/toggle/all", want: []string{"mw /toggle/all", "all "}},
+		{url: "/toggle/a%20b?on=1#x", want: []string{"mw /toggle/{id}", "toggle a b"}},
+		{url: "streamdeck://plugins/message/com.example.plugin/toggle/cam", want: []string{"mw /toggle/{id}", "toggle cam"}},
+		{url: "/files/a/b.txt", want: []string{"mw /files/{path...}", "files a/b.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			got = nil
+			if err := r.Dispatch(context.Background(), nil, tt.url); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Dispatch(%q) called %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+
+	if err := r.Dispatch(context.Background(), nil, "/toggle/mic/extra"); !errors.Is(err, streamdeck.ErrDeepLinkNotFound) {
+		t.Errorf("Dispatch(unknown) = %v, want ErrDeepLinkNotFound", err)
+	}
+	if err := r.Dispatch(context.Background(), nil, "streamdeck://other/path"); !errors.Is(err, streamdeck.ErrInvalidDeepLink) {
+		t.Errorf("Dispatch(other host) = %v, want ErrInvalidDeepLink", err)
+	}
+}
+
+func TestBindQuery(t *testing.T) {
+	var q struct {
+		Name   string   `query:"name"`
+		Volume int      `json:"volume"`
+		Mute   bool     `query:"mute"`
+		Tags   []string `query:"tag"`
+		Ratio  float64
+	}
+	query, _ := url.ParseQuery("name=mic&volume=42&mute&tag=a&tag=b&Ratio=0.5")
+	if err := streamdeck.BindQuery(query, &q); err != nil {
+		t.Fatal(err)
+	}
+	if q.Name != "mic" || q.Volume != 42 || !q.Mute || strings.Join(q.Tags, ",") != "a,b" || q.Ratio != 0.5 {
+		t.Errorf("BindQuery() = %+v", q)
+	}
+
+	query, _ = url.ParseQuery("volume=loud")
+	if err := streamdeck.BindQuery(query, &q); !errors.Is(err, streamdeck.ErrInvalidDeepLink) {
+		t.Errorf("BindQuery(volume=loud) = %v, want ErrInvalidDeepLink", err)
+	}
+}
+
+func TestMountDeepLinks(t *testing.T) {
+	srv := sdtest.NewServer(t)
+	client := streamdeck.NewClient(context.Background(), srv.RegistrationParams())
+	r := streamdeck.NewDeepLinkRouter()
+	type volume struct {
+		Level int `query:"level"`
+	}
+	received := make(chan string, 1)
+	streamdeck.HandleDeepLink(r, "/volume/{device}", func(ctx context.Context, client *streamdeck.Client, link *streamdeck.DeepLink, q volume) error {
+		received <- fmt.Sprintf("%s %d", link.Param("device"), q.Level)
+		return nil
+	})
+	client.MountDeepLinks(r)
+	srv.RunClient(client)
+
+	srv.DidReceiveDeepLink("/volume/speakers?level=3")
+	if got := <-received; got != "speakers 3" {
+		t.Errorf("received %q", got)
+	}
+}
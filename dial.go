@@ -0,0 +1,294 @@
+package streamdeck
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	sdcontext "github.com/FlowingSPDG/streamdeck/context"
+	"golang.org/x/xerrors"
+)
+
+// DefaultPersistDelay Delay before a changed dial value is saved to the settings.
+const DefaultPersistDelay = 500 * time.Millisecond
+
+// Acceleration Convert ticks of a single dialRotate event to steps. Fast rotation is delivered as more ticks per event.
+type Acceleration func(ticks int) float64
+
+// LinearAcceleration one step per tick.
+func LinearAcceleration(ticks int) float64 {
+	return float64(ticks)
+}
+
+// QuadraticAcceleration steps grow with the square of ticks, so fast rotation covers large ranges quickly.
+func QuadraticAcceleration(ticks int) float64 {
+	return float64(ticks * abs(ticks))
+}
+
+// DialPressBehavior What pressing the dial does to a DialValue.
+type DialPressBehavior int
+
+const (
+	// DialPressNone pressing does nothing (0)
+	DialPressNone DialPressBehavior = iota
+	// DialPressReset pressing resets the value to Default (1)
+	DialPressReset
+	// DialPressFine rotating while pressed changes the value by FineStep (2)
+	DialPressFine
+)
+
+// DialValueConfig Configuration of a DialValue.
+type DialValueConfig struct {
+	// Min, Max bounds of the value. The value is unbounded when both are 0.
+	Min, Max float64
+	// Default initial value, and value restored by DialPressReset.
+	Default float64
+	// Step change per tick. 1 when 0.
+	Step float64
+	// FineStep change per tick while pressed with DialPressFine. Step/10 when 0.
+	FineStep float64
+	// Wrap wraps around the bounds instead of clamping. Max is exclusive then, e.g. 0 and 360 for an angle.
+	// Bounds given as Max < Min are swapped, and equal bounds don't wrap.
+	Wrap bool
+	// Acceleration LinearAcceleration when nil.
+	Acceleration Acceleration
+	Press        DialPressBehavior
+	// SettingsKey key of the settings the value is saved under. The value is not saved when empty.
+	SettingsKey string
+	// PersistDelay debounce delay before saving. DefaultPersistDelay when 0.
+	PersistDelay time.Duration
+	// Format formats the value for display. strconv.FormatFloat when nil.
+	Format func(v float64) string
+	// Render displays the value. When nil, the value and indicator items of the $B1 layout are updated.
+	Render func(ctx context.Context, client *Client, v float64) error
+}
+
+// DialValue A value controlled by the dial of an encoder action, for each action instance.
+type DialValue struct {
+	cfg      DialValueConfig
+	mu       sync.Mutex
+	states   map[string]*dialState
+	onChange []func(ctx context.Context, client *Client, v float64) error
+}
+
+type dialState struct {
+	value    float64
+	settings map[string]json.RawMessage
+	timer    *time.Timer
+}
+
+// NewDialValue Bind a new dial value to an encoder action.
+func NewDialValue(action *Action, cfg DialValueConfig) *DialValue {
+	if cfg.Max < cfg.Min {
+		cfg.Min, cfg.Max = cfg.Max, cfg.Min
+	}
+	if cfg.Max == cfg.Min {
+		// there is no span to wrap around
+		cfg.Wrap = false
+	}
+	if cfg.Step == 0 {
+		cfg.Step = 1
+	}
+	if cfg.FineStep == 0 {
+		cfg.FineStep = cfg.Step / 10
+	}
+	if cfg.Acceleration == nil {
+		cfg.Acceleration = LinearAcceleration
+	}
+	if cfg.PersistDelay == 0 {
+		cfg.PersistDelay = DefaultPersistDelay
+	}
+	if cfg.Format == nil {
+		cfg.Format = func(v float64) string {
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+
+	d := &DialValue{
+		cfg:    cfg,
+		states: map[string]*dialState{},
+	}
+	if d.cfg.Render == nil {
+		d.cfg.Render = d.renderB1
+	}
+
+	OnWillAppear(action, func(ctx context.Context, client *Client, p WillAppearPayload[map[string]json.RawMessage]) error {
+		d.load(ctx, p.Settings)
+		return d.render(ctx, client)
+	})
+
+	OnDidReceiveSettings(action, func(ctx context.Context, client *Client, p DidReceiveSettingsPayload[map[string]json.RawMessage]) error {
+		d.load(ctx, p.Settings)
+		return d.render(ctx, client)
+	})
+
+	OnDialRotate(action, func(ctx context.Context, client *Client, p DialRotatePayload[map[string]json.RawMessage]) error {
+		d.mu.Lock()
+		s := d.state(ctx)
+		v := d.apply(s.value, p.Ticks, p.Pressed && d.cfg.Press == DialPressFine)
+		d.mu.Unlock()
+		return d.Set(ctx, client, v)
+	})
+
+	OnDialDown(action, func(ctx context.Context, client *Client, p DialDownPayload[map[string]json.RawMessage]) error {
+		if d.cfg.Press != DialPressReset {
+			return nil
+		}
+		return d.Set(ctx, client, d.cfg.Default)
+	})
+
+	OnWillDisappear(action, func(ctx context.Context, client *Client, p WillDisappearPayload[map[string]json.RawMessage]) error {
+		d.mu.Lock()
+		s, ok := d.states[sdcontext.Context(ctx)]
+		delete(d.states, sdcontext.Context(ctx))
+		pending := ok && s.timer != nil && s.timer.Stop()
+		d.mu.Unlock()
+
+		// flush pending save
+		if pending {
+			return d.persist(ctx, client, s)
+		}
+		return nil
+	})
+
+	return d
+}
+
+// OnChange Register a handler called after the value of an action instance changes.
+func (d *DialValue) OnChange(handler func(ctx context.Context, client *Client, v float64) error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onChange = append(d.onChange, handler)
+}
+
+// Value Get the current value of the action instance of ctx.
+func (d *DialValue) Value(ctx context.Context) float64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if s, ok := d.states[sdcontext.Context(ctx)]; ok {
+		return s.value
+	}
+	return d.cfg.Default
+}
+
+// Set Set the value of the action instance of ctx. It is bounded, rendered and saved as if the dial was rotated.
+func (d *DialValue) Set(ctx context.Context, client *Client, v float64) error {
+	d.mu.Lock()
+	s := d.state(ctx)
+	v = d.bound(v)
+	changed := s.value != v
+	s.value = v
+	if changed && d.cfg.SettingsKey != "" {
+		if s.timer != nil {
+			s.timer.Stop()
+		}
+		s.timer = time.AfterFunc(d.cfg.PersistDelay, func() {
+			if err := d.persist(ctx, client, s); err != nil {
+				logger.Printf("failed to save dial value: %v\n", err)
+			}
+		})
+	}
+	handlers := d.onChange
+	d.mu.Unlock()
+
+	if err := d.render(ctx, client); err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+	for _, h := range handlers {
+		if err := h(ctx, client, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// state must be called with d.mu held.
+func (d *DialValue) state(ctx context.Context) *dialState {
+	s, ok := d.states[sdcontext.Context(ctx)]
+	if !ok {
+		s = &dialState{value: d.cfg.Default, settings: map[string]json.RawMessage{}}
+		d.states[sdcontext.Context(ctx)] = s
+	}
+	return s
+}
+
+func (d *DialValue) load(ctx context.Context, settings map[string]json.RawMessage) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s := d.state(ctx)
+	if settings != nil {
+		s.settings = settings
+	}
+	if d.cfg.SettingsKey == "" {
+		return
+	}
+	if raw, ok := s.settings[d.cfg.SettingsKey]; ok {
+		var v float64
+		if err := json.Unmarshal(raw, &v); err == nil {
+			s.value = d.bound(v)
+		}
+	}
+}
+
+func (d *DialValue) persist(ctx context.Context, client *Client, s *dialState) error {
+	d.mu.Lock()
+	b, err := json.Marshal(s.value)
+	if err != nil {
+		d.mu.Unlock()
+		return xerrors.Errorf("%w: %v", ErrJSONMarshal, err)
+	}
+	s.settings[d.cfg.SettingsKey] = b
+	settings := make(map[string]json.RawMessage, len(s.settings))
+	for k, v := range s.settings {
+		settings[k] = v
+	}
+	d.mu.Unlock()
+
+	return client.SetSettings(ctx, settings)
+}
+
+func (d *DialValue) render(ctx context.Context, client *Client) error {
+	return d.cfg.Render(ctx, client, d.Value(ctx))
+}
+
+func (d *DialValue) renderB1(ctx context.Context, client *Client, v float64) error {
+	payload := LayoutB1Feedback{Value: &TextItem{Value: d.cfg.Format(v)}}
+	if d.cfg.Max > d.cfg.Min {
+		payload.Indicator = &BarItem{Value: (v - d.cfg.Min) / (d.cfg.Max - d.cfg.Min) * 100}
+	}
+	return client.SetLayoutFeedback(ctx, payload)
+}
+
+// apply applies ticks of a dialRotate event to v.
+func (d *DialValue) apply(v float64, ticks int, fine bool) float64 {
+	step := d.cfg.Step
+	if fine {
+		step = d.cfg.FineStep
+	}
+	return d.bound(v + d.cfg.Acceleration(ticks)*step)
+}
+
+func (d *DialValue) bounded() bool {
+	return d.cfg.Min != 0 || d.cfg.Max != 0
+}
+
+func (d *DialValue) bound(v float64) float64 {
+	if !d.bounded() {
+		return v
+	}
+	if d.cfg.Wrap {
+		span := d.cfg.Max - d.cfg.Min
+		if v < d.cfg.Min || v >= d.cfg.Max {
+			v = d.cfg.Min + math.Mod(math.Mod(v-d.cfg.Min, span)+span, span)
+		}
+		return v
+	}
+	return math.Min(d.cfg.Max, math.Max(d.cfg.Min, v))
+}
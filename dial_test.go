@@ -0,0 +1,53 @@
+package streamdeck
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestDialValueApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   DialValueConfig
+		v     float64
+		ticks int
+		fine  bool
+		want  float64
+	}{
+		{name: "unbounded", cfg: DialValueConfig{}, v: 0, ticks: -3, want: -3},
+		{name: "step", cfg: DialValueConfig{Min: 0, Max: 100, Step: 5}, v: 50, ticks: 2, want: 60},
+		{name: "clamp max", cfg: DialValueConfig{Min: 0, Max: 100, Step: 5}, v: 95, ticks: 3, want: 100},
+		{name: "clamp min", cfg: DialValueConfig{Min: 0, Max: 100}, v: 1, ticks: -3, want: 0},
+		{name: "wrap max", cfg: DialValueConfig{Min: 0, Max: 360, Step: 10, Wrap: true}, v: 350, ticks: 2, want: 10},
+		{name: "wrap min", cfg: DialValueConfig{Min: 0, Max: 360, Step: 10, Wrap: true}, v: 0, ticks: -1, want: 350},
+		{name: "reversed bounds", cfg: DialValueConfig{Min: 100, Max: 0, Step: 5}, v: 95, ticks: 3, want: 100},
+		{name: "wrap reversed bounds", cfg: DialValueConfig{Min: 360, Max: 0, Step: 10, Wrap: true}, v: 350, ticks: 2, want: 10},
+		{name: "wrap empty bounds", cfg: DialValueConfig{Min: 5, Max: 5, Wrap: true}, v: 5, ticks: 2, want: 5},
+		{name: "quadratic", cfg: DialValueConfig{Acceleration: QuadraticAcceleration}, v: 0, ticks: -3, want: -9},
+		{name: "fine", cfg: DialValueConfig{Step: 1, Press: DialPressFine}, v: 0, ticks: 5, fine: true, want: 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDialValue(newAction("dev.example.dial"), tt.cfg)
+			if got := d.apply(tt.v, tt.ticks, tt.fine); got != tt.want {
+				t.Errorf("apply(%v, %d) = %v, want %v", tt.v, tt.ticks, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDialValueLoad(t *testing.T) {
+	d := NewDialValue(newAction("dev.example.dial"), DialValueConfig{Min: 0, Max: 10, Default: 5, SettingsKey: "volume"})
+
+	ctx := context.Background()
+	if got := d.Value(ctx); got != 5 {
+		t.Errorf("Value() = %v, want default 5", got)
+	}
+
+	d.load(ctx, map[string]json.RawMessage{"volume": json.RawMessage("42")})
+	if got := d.Value(ctx); got != 10 {
+		t.Errorf("Value() = %v, want clamped 10", got)
+	}
+}
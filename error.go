@@ -14,4 +14,13 @@ var (
 	ErrWriteFailed            = errors.New("write failed")
 	ErrReadFailed             = errors.New("read failed")
 	ErrInvalidMessage         = errors.New("invalid message")
+	ErrImageNotFound          = errors.New("image not found")
+	ErrTransitionVetoed       = errors.New("state transition vetoed")
+	ErrUnknownProfile         = errors.New("profile is not in the manifest")
+	ErrProfileDeviceType      = errors.New("profile is not for the device type")
+	ErrUnknownDevice          = errors.New("device type is unknown")
+	ErrDeepLinkNotFound       = errors.New("no route for deep link")
+	ErrInvalidDeepLink        = errors.New("invalid deep link")
+	ErrAppNotMonitored        = errors.New("application is not in ApplicationsToMonitor")
+	ErrInvalidPropertyInspectorParams = errors.New("invalid property inspector arguments")
 )
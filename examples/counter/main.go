@@ -8,14 +8,44 @@ import (
 	"strconv"
 
 	"github.com/FlowingSPDG/streamdeck"
+	"github.com/FlowingSPDG/streamdeck/manifest"
 )
 
+//go:generate go run . manifest manifest.json
+
+// plugin metadata of the manifest. Actions are generated from the specs registered in setup.
+var plugin = manifest.Manifest{
+	Author:      "Sam Rose",
+	CodePath:    "counter.exe",
+	Description: "Count something!",
+	Icon:        "images/icon",
+	Name:        "Counter",
+	URL:         "https://samwho.dev",
+	Version:     "0.1",
+	OS: []manifest.OS{
+		{Platform: manifest.Mac, MinimumVersion: "10.11"},
+		{Platform: manifest.Windows, MinimumVersion: "10"},
+	},
+	Software: manifest.Software{MinimumVersion: "4.1"},
+}
+
 type Settings struct {
 	Counter int `json:"counter"`
 }
 
 func main() {
 	ctx := context.Background()
+
+	// "counter manifest <path>" writes the manifest instead of running the plugin
+	if len(os.Args) == 3 && os.Args[1] == "manifest" {
+		client := streamdeck.NewClient(ctx, streamdeck.RegistrationParams{})
+		setup(client)
+		if err := client.WriteManifest(os.Args[2], plugin); err != nil {
+			panic(err)
+		}
+		return
+	}
+
 	if err := run(ctx); err != nil {
 		panic(err)
 	}
@@ -34,7 +64,15 @@ func run(ctx context.Context) error {
 }
 
 func setup(client *streamdeck.Client) {
-	action := client.Action("dev.samwho.streamdeck.counter")
+	action := client.RegisterAction(streamdeck.ActionSpec{
+		UUID:    "dev.samwho.streamdeck.counter",
+		Name:    "Counter",
+		Icon:    "images/icon",
+		Tooltip: "Count something!",
+		States: []manifest.State{
+			{Image: "images/icon", TitleAlignment: "middle", FontSize: "24"},
+		},
+	})
 
 	action.RegisterHandler(streamdeck.WillAppear, func(ctx context.Context, client *streamdeck.Client, event streamdeck.Event) error {
 		// Settings will be passed through the event.
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -9,6 +8,7 @@ import (
 	"net/http"
 
 	"github.com/FlowingSPDG/streamdeck"
+	"github.com/FlowingSPDG/streamdeck/sdtest"
 	"github.com/c-bata/go-prompt"
 	"github.com/olahol/melody"
 )
@@ -24,50 +24,138 @@ var (
 
 // Mocking software
 
+const (
+	pluginUUID = "C4B10610C736B410125C84369621E33E"
+
+	// maxMessageSize messages carry base64 images and settings, way above the melody default of 512 bytes
+	maxMessageSize = 16 << 20
+)
+
+const info = `{"application":{"font":"Segoe UI","language":"ja","platform":"windows","platformVersion":"10.0.19043","version":"6.0.1.17722"},"colors":{"buttonMouseOverBackgroundColor":"#464646FF","buttonPressedBackgroundColor":"#303030FF","buttonPressedBorderColor":"#646464FF","buttonPressedTextColor":"#969696FF","highlightColor":"#0078FFFF"},"devicePixelRatio":1,"devices":[{"id":"7EAEBEB876DC1927A04E7E31610731CF","name":"StreamDeck1","size":{"columns":5,"rows":3},"type":0},{"id":"3B1C10164F4A9B3850E88CDA0324656D","name":"Stream Deck XL","size":{"columns":8,"rows":4},"type":2},{"id":"934035044D1CF9F56EBA9607286EFE53","name":"Stream Deck","size":{"columns":5,"rows":3},"type":0},{"id":"D76B6C8774E20D878B50E12759E3CFAA","name":"Stream Deck Mini","size":{"columns":3,"rows":2},"type":1},{"id":"5A1C3E09B1E2F66A0D4C1B3E8F2D7C90","name":"Stream Deck +","size":{"columns":4,"rows":2},"type":7}],"plugin":{"uuid":"dev.samwho.cpu","version":"0.1"}}`
+
 // mockServer with websocket server
 type mockServer struct {
-	s *melody.Melody // Websocket sessions
+	s    *melody.Melody // Websocket sessions of the plugin
+	ui   *melody.Melody // Websocket sessions of the web UI
+	deck *sdtest.Deck   // Virtual devices and action instances
 }
 
 func (m *mockServer) SendEvent(event streamdeck.Event) error {
-	event.Action = action
-	event.Context = "ff158f4368c8b128c7ae45a99c88baa8"
 	j, _ := json.Marshal(event)
 	log.Printf("Sending event: %s\n", j)
 	return m.s.Broadcast(j)
 }
 
-func (m *mockServer) KeyDown(ctx context.Context, payload any) error {
-	ev := streamdeck.NewEvent(ctx, streamdeck.KeyDown, payload)
-	return m.SendEvent(ev)
+// first get the first action instance on the deck, the target of prompt commands
+func (m *mockServer) first() (sdtest.Key, bool) {
+	keys := m.deck.Keys()
+	if len(keys) == 0 {
+		log.Println("No action on the deck. Place one from the web UI")
+		return sdtest.Key{}, false
+	}
+	return keys[0], true
 }
 
-func (m *mockServer) WillAppear(ctx context.Context, payload any) error {
-	ev := streamdeck.NewEvent(ctx, streamdeck.WillAppear, payload)
-	return m.SendEvent(ev)
+func (m *mockServer) KeyDown(k sdtest.Key) error {
+	return m.SendEvent(keyEvent(k, streamdeck.KeyDown, streamdeck.KeyDownPayload[json.RawMessage]{
+		Settings:        k.Settings,
+		Coordinates:     k.Coordinates,
+		State:           k.State,
+		IsInMultiAction: k.IsInMultiAction,
+	}))
 }
 
-func (m *mockServer) WillDisappear(ctx context.Context, payload any) error {
-	ev := streamdeck.NewEvent(ctx, streamdeck.WillDisappear, payload)
-	return m.SendEvent(ev)
+func (m *mockServer) KeyUp(k sdtest.Key) error {
+	return m.SendEvent(keyEvent(k, streamdeck.KeyUp, streamdeck.KeyUpPayload[json.RawMessage]{
+		Settings:        k.Settings,
+		Coordinates:     k.Coordinates,
+		State:           k.State,
+		IsInMultiAction: k.IsInMultiAction,
+	}))
 }
 
-func (m *mockServer) KeyUp(ctx context.Context, payload any) error {
-	ev := streamdeck.NewEvent(ctx, streamdeck.KeyUp, payload)
-	return m.SendEvent(ev)
+func (m *mockServer) WillAppear(k sdtest.Key) error {
+	return m.SendEvent(keyEvent(k, streamdeck.WillAppear, streamdeck.WillAppearPayload[json.RawMessage]{
+		Settings:        k.Settings,
+		Coordinates:     k.Coordinates,
+		State:           k.State,
+		IsInMultiAction: k.IsInMultiAction,
+	}))
 }
 
-func (m *mockServer) SendToPlugin(ctx context.Context, payload any) error {
-	ev := streamdeck.NewEvent(ctx, streamdeck.SendToPlugin, payload)
-	return m.SendEvent(ev)
+func (m *mockServer) WillDisappear(k sdtest.Key) error {
+	return m.SendEvent(keyEvent(k, streamdeck.WillDisappear, streamdeck.WillDisappearPayload[json.RawMessage]{
+		Settings:        k.Settings,
+		Coordinates:     k.Coordinates,
+		State:           k.State,
+		IsInMultiAction: k.IsInMultiAction,
+	}))
 }
 
-func newMock() *mockServer {
-	m := melody.New()
-	m.HandleMessage(func(s *melody.Session, msg []byte) {
-		log.Printf("RCV: %s\n", msg)
-	})
-	return &mockServer{s: m}
+func (m *mockServer) SendToPlugin(k sdtest.Key, payload any) error {
+	return m.SendEvent(keyEvent(k, streamdeck.SendToPlugin, payload))
+}
+
+func keyEvent(k sdtest.Key, name string, payload any) streamdeck.Event {
+	return streamdeck.Event{
+		Action:  k.Action,
+		Event:   name,
+		Context: k.Context,
+		Device:  k.Device,
+		Payload: payload,
+	}
+}
+
+// registered the plugin registered: announce the devices and the action instances, like the Stream Deck software does on startup
+func (m *mockServer) registered() {
+	for _, dev := range m.deck.Devices() {
+		m.SendEvent(streamdeck.Event{
+			Event:  streamdeck.DeviceDidConnect,
+			Device: dev.ID,
+			DeviceInfo: streamdeck.DeviceInfo{
+				DeviceName: dev.Name,
+				Type:       dev.Type,
+				Size:       streamdeck.DeviceSize{Columns: dev.Columns, Rows: dev.Rows},
+			},
+		})
+	}
+	for _, k := range m.deck.Keys() {
+		m.WillAppear(k)
+	}
+}
+
+func (m *mockServer) handlePlugin(s *melody.Session, msg []byte) {
+	log.Printf("RCV: %s\n", msg)
+
+	var event streamdeck.Event
+	if err := json.Unmarshal(msg, &event); err != nil {
+		log.Printf("Failed to unmarshal message: %v\n", err)
+		return
+	}
+	if event.Event == "registerPlugin" {
+		m.registered()
+		return
+	}
+
+	reply, err := m.deck.Apply(event)
+	if err != nil {
+		log.Printf("Failed to apply %s: %v\n", event.Event, err)
+		return
+	}
+	if reply != nil {
+		m.SendEvent(*reply)
+	}
+	m.broadcastState()
+}
+
+func newMock(deck *sdtest.Deck) *mockServer {
+	m := &mockServer{s: melody.New(), ui: melody.New(), deck: deck}
+	m.s.Config.MaxMessageSize = maxMessageSize
+	m.ui.Config.MaxMessageSize = maxMessageSize
+	m.s.HandleMessage(m.handlePlugin)
+	m.ui.HandleConnect(m.sendState)
+	m.ui.HandleMessage(m.handleUI)
+	return m
 }
 
 // TODO: 各種Actionのmock挙動を網羅する
@@ -89,17 +177,30 @@ func main() {
 	flag.StringVar(&action, "action", "dev.samwho.streamdeck.cpu", "Action ID")
 	flag.Parse()
 
-	ctx := context.Background()
-	m := newMock()
+	var i streamdeck.Info
+	if err := json.Unmarshal([]byte(info), &i); err != nil {
+		panic(err)
+	}
+	deck := sdtest.NewDeck(i)
+	// Place the action on the first key so prompt commands have a target
+	if _, err := deck.Place(action, i.Devices[0].ID, sdtest.Keypad, 0, 0, nil); err != nil {
+		panic(err)
+	}
+	m := newMock(deck)
 
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		m.s.HandleRequest(w, r)
 	})
+	http.Handle("/ui/", uiHandler())
+	http.HandleFunc("/ui/ws", func(w http.ResponseWriter, r *http.Request) {
+		m.ui.HandleRequest(w, r)
+	})
 
 	p := 5000
 	log.Println("STARTING on", p)
-	line := fmt.Sprintf(`-port %d -pluginUUID C4B10610C736B410125C84369621E33E -registerEvent registerPlugin -info {"application":{"font":"Segoe UI","language":"ja","platform":"windows","platformVersion":"10.0.19043","version":"6.0.1.17722"},"colors":{"buttonMouseOverBackgroundColor":"#464646FF","buttonPressedBackgroundColor":"#303030FF","buttonPressedBorderColor":"#646464FF","buttonPressedTextColor":"#969696FF","highlightColor":"#0078FFFF"},"devicePixelRatio":1,"devices":[{"id":"7EAEBEB876DC1927A04E7E31610731CF","name":"StreamDeck1","size":{"columns":5,"rows":3},"type":0},{"id":"3B1C10164F4A9B3850E88CDA0324656D","name":"Stream Deck XL","size":{"columns":8,"rows":4},"type":2},{"id":"934035044D1CF9F56EBA9607286EFE53","name":"Stream Deck","size":{"columns":5,"rows":3},"type":0},{"id":"D76B6C8774E20D878B50E12759E3CFAA","name":"Stream Deck Mini","size":{"columns":3,"rows":2},"type":1}],"plugin":{"uuid":"dev.samwho.cpu","version":"0.1"}} `, p)
+	line := fmt.Sprintf(`-port %d -pluginUUID %s -registerEvent registerPlugin -info %s `, p, pluginUUID, info)
 	log.Printf("execute your plugin with following command line: \n%s\n", line)
+	log.Printf("open the virtual deck on http://localhost:%d/ui/\n", p)
 
 	// Listen http on localhost:5000
 	go func() {
@@ -111,21 +212,26 @@ func main() {
 	// Handle prompt
 	for {
 		t := prompt.Input("StreamDeck Action > ", completer)
-		switch t {
-		case exit:
+		if t == exit {
 			return
+		}
+		k, ok := m.first()
+		if !ok {
+			continue
+		}
+		switch t {
 		case streamdeck.KeyDown:
-			m.KeyDown(ctx, nil)
+			m.KeyDown(k)
 		case streamdeck.KeyUp:
-			m.KeyUp(ctx, nil)
+			m.KeyUp(k)
 		case streamdeck.SendToPlugin:
-			m.SendToPlugin(ctx, nil)
+			m.SendToPlugin(k, nil)
 		case streamdeck.WillAppear:
-			m.WillAppear(ctx, nil)
+			m.WillAppear(k)
 		case streamdeck.WillDisappear:
-			m.WillDisappear(ctx, nil)
+			m.WillDisappear(k)
 		default:
-			m.SendEvent(streamdeck.NewEvent(ctx, t, nil))
+			m.SendEvent(keyEvent(k, t, nil))
 		}
 	}
 
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/FlowingSPDG/streamdeck"
+	"github.com/FlowingSPDG/streamdeck/sdtest"
+	"github.com/olahol/melody"
+)
+
+// Web UI showing the virtual devices of the mock
+
+//go:embed index.html
+var static embed.FS
+
+func uiHandler() http.Handler {
+	return http.StripPrefix("/ui/", http.FileServer(http.FS(static)))
+}
+
+// uiState state of the deck sent to the web UI on every change
+type uiState struct {
+	Action  string                 `json:"action"`
+	Devices []sdtest.VirtualDevice `json:"devices"`
+	Keys    []sdtest.Key           `json:"keys"`
+}
+
+// uiCommand interaction of the web UI with the virtual deck
+type uiCommand struct {
+	Type       string          `json:"type"`
+	Context    string          `json:"context"`
+	Action     string          `json:"action"`
+	Device     string          `json:"device"`
+	Controller string          `json:"controller"`
+	Column     int             `json:"column"`
+	Row        int             `json:"row"`
+	Ticks      int             `json:"ticks"`
+	TapPos     [2]int          `json:"tapPos"`
+	Settings   json.RawMessage `json:"settings"`
+}
+
+func (m *mockServer) state() []byte {
+	b, _ := json.Marshal(uiState{
+		Action:  action,
+		Devices: m.deck.Devices(),
+		Keys:    m.deck.Keys(),
+	})
+	return b
+}
+
+func (m *mockServer) sendState(s *melody.Session) {
+	s.Write(m.state())
+}
+
+func (m *mockServer) broadcastState() {
+	m.ui.Broadcast(m.state())
+}
+
+func (m *mockServer) handleUI(s *melody.Session, msg []byte) {
+	var cmd uiCommand
+	if err := json.Unmarshal(msg, &cmd); err != nil {
+		log.Printf("Failed to unmarshal UI command: %v\n", err)
+		return
+	}
+	defer m.broadcastState()
+
+	if cmd.Type == "place" {
+		k, err := m.deck.Place(cmd.Action, cmd.Device, cmd.Controller, cmd.Column, cmd.Row, nil)
+		if err != nil {
+			log.Printf("Failed to place %s: %v\n", cmd.Action, err)
+			return
+		}
+		m.WillAppear(k)
+		return
+	}
+
+	k, ok := m.deck.Key(cmd.Context)
+	if !ok {
+		log.Printf("Unknown context %q\n", cmd.Context)
+		return
+	}
+	switch cmd.Type {
+	case "remove":
+		m.deck.Remove(k.Context)
+		m.WillDisappear(k)
+	case streamdeck.KeyDown:
+		m.KeyDown(k)
+	case streamdeck.KeyUp:
+		// multi-state actions advance on release, like the Stream Deck software does.
+		m.deck.AdvanceState(k.Context)
+		m.KeyUp(k)
+	case streamdeck.DialDown:
+		m.SendEvent(keyEvent(k, streamdeck.DialDown, streamdeck.DialDownPayload[json.RawMessage]{
+			Settings:    k.Settings,
+			Coordinates: k.Coordinates,
+			Controller:  sdtest.Encoder,
+		}))
+	case streamdeck.DialUp:
+		m.SendEvent(keyEvent(k, streamdeck.DialUp, streamdeck.DialUpPayload[json.RawMessage]{
+			Settings:    k.Settings,
+			Coordinates: k.Coordinates,
+			Controller:  sdtest.Encoder,
+		}))
+	case streamdeck.DialRotate:
+		m.SendEvent(keyEvent(k, streamdeck.DialRotate, streamdeck.DialRotatePayload[json.RawMessage]{
+			Settings:    k.Settings,
+			Coordinates: k.Coordinates,
+			Ticks:       cmd.Ticks,
+		}))
+	case streamdeck.TouchTap:
+		m.SendEvent(keyEvent(k, streamdeck.TouchTap, streamdeck.TouchTapPayload[json.RawMessage]{
+			Settings:    k.Settings,
+			Coordinates: k.Coordinates,
+			TapPos:      cmd.TapPos,
+		}))
+	case "settings":
+		if !json.Valid(cmd.Settings) {
+			log.Printf("Invalid settings for %s: %s\n", k.Context, cmd.Settings)
+			return
+		}
+		m.deck.Update(k.Context, func(k *sdtest.Key) {
+			k.Settings = cmd.Settings
+		})
+		m.SendEvent(keyEvent(k, streamdeck.DidReceiveSettings, streamdeck.DidReceiveSettingsPayload[json.RawMessage]{
+			Settings:        cmd.Settings,
+			Coordinates:     k.Coordinates,
+			IsInMultiAction: k.IsInMultiAction,
+		}))
+	default:
+		log.Printf("Unknown UI command %q\n", cmd.Type)
+	}
+}
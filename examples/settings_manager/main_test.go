@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/FlowingSPDG/streamdeck"
+	"github.com/FlowingSPDG/streamdeck/sdtest"
+)
+
+func TestPropertyInspector(t *testing.T) {
+	srv := sdtest.NewServer(t)
+	client := streamdeck.NewClient(context.Background(), srv.RegistrationParams())
+	sm := NewSettingsManager()
+	setup(client, sm)
+	srv.RunClient(client)
+
+	inst := sdtest.NewInstance("dev.samwho.streamdeck.settings_manager", 0, 0)
+	settings := Settings{Counter: 4, ButtonText: "Hi", Color: "red"}
+	srv.WillAppear(inst, settings)
+	srv.ExpectSetTitle(inst.Context, "Hi\n4")
+
+	pi := srv.OpenPropertyInspector(inst, settings)
+	pi.SendToPlugin(PropertyInspectorMessage{Action: "getAllStates"})
+	var states AllStatesResponse
+	pi.Expect(&states)
+	if states.Action != "allStates" {
+		t.Errorf("Action = %q, want allStates", states.Action)
+	}
+	if got := states.States[inst.Context].Settings.Counter; got != 4 {
+		t.Errorf("Counter = %d, want 4", got)
+	}
+
+	pi.SendToPlugin(PropertyInspectorMessage{Action: "resetAll"})
+	var reset ResetCompleteResponse
+	pi.Expect(&reset)
+	if reset.Action != "resetComplete" {
+		t.Errorf("Action = %q, want resetComplete", reset.Action)
+	}
+	if state, _ := sm.LoadButtonState(inst.Context); state.Settings.Counter != 0 {
+		t.Errorf("Counter = %d after reset, want 0", state.Settings.Counter)
+	}
+
+	// messages sent once the property inspector is closed are not received
+	pi.Close(settings)
+	srv.SendToPlugin(inst, PropertyInspectorMessage{Action: "getAllStates"})
+	srv.Expect(streamdeck.SendToPropertyInspector, inst.Context, func(event streamdeck.Event) bool {
+		var p AllStatesResponse
+		return event.UnmarshalPayload(&p) == nil && p.Action == "allStates" && p.States[inst.Context].Settings.Counter == 0
+	})
+	if n := len(pi.Messages()); n != 2 {
+		t.Errorf("property inspector received %d messages, want 2", n)
+	}
+}
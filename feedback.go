@@ -0,0 +1,224 @@
+package streamdeck
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Built-in Stream Deck + touch display layouts.
+// refer to https://docs.elgato.com/streamdeck/sdk/guides/dials#built-in-layouts
+const (
+	// LayoutX1 Icon layout. title, icon.
+	LayoutX1 = "$X1"
+	// LayoutA0 Full canvas layout. title, full-canvas.
+	LayoutA0 = "$A0"
+	// LayoutA1 Value layout. title, icon, value.
+	LayoutA1 = "$A1"
+	// LayoutB1 Indicator layout. title, icon, value, indicator(bar).
+	LayoutB1 = "$B1"
+	// LayoutB2 Gradient indicator layout. title, icon, value, indicator(gbar).
+	LayoutB2 = "$B2"
+	// LayoutC1 Double indicator layout. title, icon1, icon2, indicator1(bar), indicator2(bar).
+	LayoutC1 = "$C1"
+)
+
+// Alignment Horizontal alignment of a text item.
+type Alignment string
+
+const (
+	// AlignLeft left
+	AlignLeft Alignment = "left"
+	// AlignCenter center
+	AlignCenter Alignment = "center"
+	// AlignRight right
+	AlignRight Alignment = "right"
+)
+
+// TextOverflow How a text item that doesn't fit in its rect is displayed.
+type TextOverflow string
+
+const (
+	// TextOverflowClip clip
+	TextOverflowClip TextOverflow = "clip"
+	// TextOverflowEllipsis ellipsis
+	TextOverflowEllipsis TextOverflow = "ellipsis"
+	// TextOverflowFade fade
+	TextOverflowFade TextOverflow = "fade"
+)
+
+// BarSubType Shape of a bar item.
+type BarSubType int
+
+const (
+	// BarRectangle rectangle (0)
+	BarRectangle BarSubType = iota
+	// BarDoubleRectangle double rectangle (1)
+	BarDoubleRectangle
+	// BarTrapezoid trapezoid (2)
+	BarTrapezoid
+	// BarDoubleTrapezoid double trapezoid (3)
+	BarDoubleTrapezoid
+	// BarGroove groove (4)
+	BarGroove
+)
+
+// Font Font of a text item.
+type Font struct {
+	Size   int `json:"size,omitempty"`
+	Weight int `json:"weight,omitempty"`
+}
+
+// Range Range of the value of a bar item.
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// TextItem Properties of a text item. Empty fields are left unchanged by setFeedback.
+type TextItem struct {
+	Value        string       `json:"value,omitempty"`
+	Alignment    Alignment    `json:"alignment,omitempty"`
+	Color        string       `json:"color,omitempty"`
+	Font         *Font        `json:"font,omitempty"`
+	TextOverflow TextOverflow `json:"text-overflow,omitempty"`
+	Background   string       `json:"background,omitempty"`
+	Enabled      *bool        `json:"enabled,omitempty"`
+	Opacity      *float64     `json:"opacity,omitempty"`
+}
+
+// PixmapItem Properties of a pixmap item. Empty fields are left unchanged by setFeedback.
+type PixmapItem struct {
+	// Value path to an image file, or base64 encoded image.
+	Value string `json:"value,omitempty"`
+	// Image encoded into Value when the item is marshaled.
+	Image      image.Image `json:"-"`
+	Background string      `json:"background,omitempty"`
+	Enabled    *bool       `json:"enabled,omitempty"`
+	Opacity    *float64    `json:"opacity,omitempty"`
+}
+
+// MarshalJSON encodes Image into the value of the item.
+func (p PixmapItem) MarshalJSON() ([]byte, error) {
+	type pixmapItem PixmapItem
+	if p.Image != nil {
+		s, err := Image(p.Image)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to encode pixmap: %w", err)
+		}
+		p.Value = s
+	}
+	return json.Marshal(pixmapItem(p))
+}
+
+// BarItem Properties of a bar item. Value is always sent.
+type BarItem struct {
+	Value         float64     `json:"value"`
+	Range         *Range      `json:"range,omitempty"`
+	SubType       *BarSubType `json:"subtype,omitempty"`
+	BarBackground string      `json:"bar_bg_c,omitempty"`
+	BarFill       string      `json:"bar_fill_c,omitempty"`
+	BarBorder     string      `json:"bar_border_c,omitempty"`
+	BorderWidth   int         `json:"border_w,omitempty"`
+	Background    string      `json:"background,omitempty"`
+	Enabled       *bool       `json:"enabled,omitempty"`
+	Opacity       *float64    `json:"opacity,omitempty"`
+}
+
+// GBarItem Properties of a gbar item, a bar with an indicator. BarFill may be a gradient, see Gradient.
+type GBarItem struct {
+	BarItem
+	BarHeight int `json:"bar_h,omitempty"`
+}
+
+// GradientStop A colour stop of a gradient. Offset is between 0 and 1.
+type GradientStop struct {
+	Offset float64
+	Color  string
+}
+
+// Gradient Generate a gradient string for BarFill, e.g. "0:#ff0000,1:#0000ff".
+func Gradient(stops ...GradientStop) string {
+	s := make([]string, 0, len(stops))
+	for _, stop := range stops {
+		s = append(s, fmt.Sprintf("%g:%s", stop.Offset, stop.Color))
+	}
+	return strings.Join(s, ",")
+}
+
+// Feedback A typed setFeedback payload, see Client.SetLayoutFeedback.
+type Feedback interface {
+	// FeedbackLayout Get the built-in layout the payload is made for, empty for custom layouts.
+	FeedbackLayout() string
+}
+
+// LayoutX1Feedback setFeedback payload for the $X1 layout.
+type LayoutX1Feedback struct {
+	Title *TextItem   `json:"title,omitempty"`
+	Icon  *PixmapItem `json:"icon,omitempty"`
+}
+
+// LayoutA0Feedback setFeedback payload for the $A0 layout.
+type LayoutA0Feedback struct {
+	Title      *TextItem   `json:"title,omitempty"`
+	FullCanvas *PixmapItem `json:"full-canvas,omitempty"`
+}
+
+// LayoutA1Feedback setFeedback payload for the $A1 layout.
+type LayoutA1Feedback struct {
+	Title *TextItem   `json:"title,omitempty"`
+	Icon  *PixmapItem `json:"icon,omitempty"`
+	Value *TextItem   `json:"value,omitempty"`
+}
+
+// LayoutB1Feedback setFeedback payload for the $B1 layout.
+type LayoutB1Feedback struct {
+	Title     *TextItem   `json:"title,omitempty"`
+	Icon      *PixmapItem `json:"icon,omitempty"`
+	Value     *TextItem   `json:"value,omitempty"`
+	Indicator *BarItem    `json:"indicator,omitempty"`
+}
+
+// LayoutB2Feedback setFeedback payload for the $B2 layout.
+type LayoutB2Feedback struct {
+	Title     *TextItem   `json:"title,omitempty"`
+	Icon      *PixmapItem `json:"icon,omitempty"`
+	Value     *TextItem   `json:"value,omitempty"`
+	Indicator *GBarItem   `json:"indicator,omitempty"`
+}
+
+// LayoutC1Feedback setFeedback payload for the $C1 layout.
+type LayoutC1Feedback struct {
+	Title      *TextItem   `json:"title,omitempty"`
+	Icon1      *PixmapItem `json:"icon1,omitempty"`
+	Icon2      *PixmapItem `json:"icon2,omitempty"`
+	Indicator1 *BarItem    `json:"indicator1,omitempty"`
+	Indicator2 *BarItem    `json:"indicator2,omitempty"`
+}
+
+// CustomFeedback setFeedback payload for a custom layout. Keys are item keys, values are *TextItem, *PixmapItem, *BarItem, *GBarItem or plain values.
+type CustomFeedback map[string]any
+
+// FeedbackLayout Get LayoutX1.
+func (LayoutX1Feedback) FeedbackLayout() string { return LayoutX1 }
+
+// FeedbackLayout Get LayoutA0.
+func (LayoutA0Feedback) FeedbackLayout() string { return LayoutA0 }
+
+// FeedbackLayout Get LayoutA1.
+func (LayoutA1Feedback) FeedbackLayout() string { return LayoutA1 }
+
+// FeedbackLayout Get LayoutB1.
+func (LayoutB1Feedback) FeedbackLayout() string { return LayoutB1 }
+
+// FeedbackLayout Get LayoutB2.
+func (LayoutB2Feedback) FeedbackLayout() string { return LayoutB2 }
+
+// FeedbackLayout Get LayoutC1.
+func (LayoutC1Feedback) FeedbackLayout() string { return LayoutC1 }
+
+// FeedbackLayout Get an empty layout, custom layouts are set by the path of their JSON file.
+func (CustomFeedback) FeedbackLayout() string { return "" }
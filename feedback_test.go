@@ -0,0 +1,139 @@
+package streamdeck
+
+import (
+	"encoding/json"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestFeedbackMarshal(t *testing.T) {
+	payload := LayoutB1Feedback{
+		Title:     &TextItem{Value: "Volume", Alignment: AlignLeft},
+		Icon:      &PixmapItem{Image: solid(color.White)},
+		Indicator: &BarItem{Value: 0, Range: &Range{Min: 0, Max: 100}},
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("failed to marshal feedback: %v", err)
+	}
+
+	var m map[string]map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatalf("failed to unmarshal feedback: %v", err)
+	}
+	if _, ok := m["value"]; ok {
+		t.Error("nil items should be omitted")
+	}
+	if v, _ := m["icon"]["value"].(string); !strings.HasPrefix(v, "data:image/png;base64,") {
+		t.Errorf("icon value = %q, want PNG data URI", v)
+	}
+	if v, ok := m["indicator"]["value"]; !ok || v != 0.0 {
+		t.Errorf("indicator value = %v, want 0", v)
+	}
+}
+
+func TestFeedbackLayout(t *testing.T) {
+	tests := []struct {
+		feedback Feedback
+		want     string
+	}{
+		{LayoutX1Feedback{}, LayoutX1},
+		{LayoutA0Feedback{}, LayoutA0},
+		{LayoutA1Feedback{}, LayoutA1},
+		{LayoutB1Feedback{}, LayoutB1},
+		{LayoutB2Feedback{}, LayoutB2},
+		{LayoutC1Feedback{}, LayoutC1},
+		{CustomFeedback{"title": &TextItem{Value: "Volume"}}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.feedback.FeedbackLayout(); got != tt.want {
+			t.Errorf("%T.FeedbackLayout() = %q, want %q", tt.feedback, got, tt.want)
+		}
+	}
+}
+
+func TestGradient(t *testing.T) {
+	got := Gradient(GradientStop{0, "#ff0000"}, GradientStop{0.5, "yellow"}, GradientStop{1, "#00ff00"})
+	if want := "0:#ff0000,0.5:yellow,1:#00ff00"; got != want {
+		t.Errorf("Gradient() = %s, want %s", got, want)
+	}
+}
+
+func TestLayoutBuilder(t *testing.T) {
+	layout, err := NewLayoutBuilder("my-layout").
+		Text("title", Rect{16, 6, 136, 24}, TextItem{Value: "Title", Alignment: AlignCenter}).
+		Pixmap("icon", Rect{16, 40, 48, 48}, PixmapItem{}).
+		Bar("bar", Rect{76, 50, 108, 20}, BarItem{Value: 50, Range: &Range{Min: 0, Max: 100}}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	b, err := json.Marshal(layout)
+	if err != nil {
+		t.Fatalf("failed to marshal layout: %v", err)
+	}
+	var m struct {
+		ID    string           `json:"id"`
+		Items []map[string]any `json:"items"`
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatalf("failed to unmarshal layout: %v", err)
+	}
+	if m.ID != "my-layout" || len(m.Items) != 3 {
+		t.Fatalf("unexpected layout %s", b)
+	}
+	if m.Items[0]["type"] != "text" || m.Items[0]["value"] != "Title" {
+		t.Errorf("unexpected text item %v", m.Items[0])
+	}
+}
+
+func TestLayoutValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *LayoutBuilder
+		wantErr string
+	}{
+		{
+			name:    "outside canvas",
+			builder: NewLayoutBuilder("l").Text("title", Rect{150, 0, 60, 20}, TextItem{}),
+			wantErr: "outside of the 200x100 canvas",
+		},
+		{
+			name:    "duplicate key",
+			builder: NewLayoutBuilder("l").Text("a", Rect{0, 0, 10, 10}, TextItem{}).ZOrder(1).Text("a", Rect{0, 20, 10, 10}, TextItem{}),
+			wantErr: "duplicate key",
+		},
+		{
+			name:    "overlap",
+			builder: NewLayoutBuilder("l").Text("a", Rect{0, 0, 50, 50}, TextItem{}).Pixmap("b", Rect{25, 25, 50, 50}, PixmapItem{}),
+			wantErr: "overlaps",
+		},
+		{
+			name:    "overlap with different zOrder",
+			builder: NewLayoutBuilder("l").Text("a", Rect{0, 0, 50, 50}, TextItem{}).ZOrder(1).Pixmap("b", Rect{25, 25, 50, 50}, PixmapItem{}),
+		},
+		{
+			name:    "invalid range",
+			builder: NewLayoutBuilder("l").Bar("a", Rect{0, 0, 50, 10}, BarItem{Range: &Range{Min: 10, Max: 0}}),
+			wantErr: "range",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder.Build()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Build() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Build() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
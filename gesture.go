@@ -0,0 +1,267 @@
+package streamdeck
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	sdcontext "github.com/FlowingSPDG/streamdeck/context"
+)
+
+// DefaultDoubleTapWindow Maximum time between two taps recognized as a double tap.
+const DefaultDoubleTapWindow = 300 * time.Millisecond
+
+// DefaultLongPressThreshold Time the key is held before a long press, used for non-positive thresholds.
+const DefaultLongPressThreshold = 500 * time.Millisecond
+
+// DefaultHoldRepeatInterval Time between hold repeats, used for non-positive intervals.
+const DefaultHoldRepeatInterval = 100 * time.Millisecond
+
+// GestureHandler Handler of a recognized gesture.
+type GestureHandler func(ctx context.Context, client *Client) error
+
+// Gestures Recognizes taps, double taps, long presses and held presses from the raw down/up events of an action, for each action instance.
+// A press handled as a long press or hold repeat is not reported as a tap.
+type Gestures struct {
+	window time.Duration
+
+	mu         sync.Mutex
+	tap        []GestureHandler
+	doubleTap  []GestureHandler
+	longPress  []timedGesture
+	holdRepeat []timedGesture
+	presses    map[string]*press
+}
+
+type timedGesture struct {
+	d       time.Duration
+	handler GestureHandler
+}
+
+type press struct {
+	gen     int
+	pressed bool
+	// consumed the current press fired a long press or hold repeat
+	consumed   bool
+	timers     []*time.Timer
+	stop       chan struct{}
+	pendingTap *time.Timer
+}
+
+// NewKeyGestures Recognize gestures from keyDown/keyUp events of the action.
+func NewKeyGestures(action *Action) *Gestures {
+	return newGestures(action, KeyDown, KeyUp)
+}
+
+// NewDialGestures Recognize gestures from dialDown/dialUp events of the encoder action.
+func NewDialGestures(action *Action) *Gestures {
+	return newGestures(action, DialDown, DialUp)
+}
+
+func newGestures(action *Action, down, up string) *Gestures {
+	g := &Gestures{
+		window:  DefaultDoubleTapWindow,
+		presses: map[string]*press{},
+	}
+
+	action.RegisterHandler(down, func(ctx context.Context, client *Client, event Event) error {
+		g.down(ctx, client)
+		return nil
+	})
+	action.RegisterHandler(up, func(ctx context.Context, client *Client, event Event) error {
+		return g.up(ctx, client)
+	})
+	action.RegisterHandler(WillDisappear, func(ctx context.Context, client *Client, event Event) error {
+		g.cancel(ctx)
+		return nil
+	})
+
+	return g
+}
+
+// SetDoubleTapWindow Set the maximum time between two taps recognized as a double tap.
+func (g *Gestures) SetDoubleTapWindow(d time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.window = d
+}
+
+// OnTap Register a handler called when the key is pressed and released.
+// When double tap handlers are registered, taps are delayed by the double tap window.
+func (g *Gestures) OnTap(handler GestureHandler) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.tap = append(g.tap, handler)
+}
+
+// OnDoubleTap Register a handler called when the key is tapped twice within the double tap window.
+func (g *Gestures) OnDoubleTap(handler GestureHandler) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.doubleTap = append(g.doubleTap, handler)
+}
+
+// OnLongPress Register a handler called once the key is held for threshold, DefaultLongPressThreshold when it is not positive.
+func (g *Gestures) OnLongPress(threshold time.Duration, handler GestureHandler) {
+	if threshold <= 0 {
+		threshold = DefaultLongPressThreshold
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.longPress = append(g.longPress, timedGesture{d: threshold, handler: handler})
+}
+
+// OnHoldRepeat Register a handler called every interval while the key is held, DefaultHoldRepeatInterval when it is not positive.
+func (g *Gestures) OnHoldRepeat(interval time.Duration, handler GestureHandler) {
+	if interval <= 0 {
+		interval = DefaultHoldRepeatInterval
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.holdRepeat = append(g.holdRepeat, timedGesture{d: interval, handler: handler})
+}
+
+func (g *Gestures) down(ctx context.Context, client *Client) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p, ok := g.presses[sdcontext.Context(ctx)]
+	if !ok {
+		p = &press{}
+		g.presses[sdcontext.Context(ctx)] = p
+	}
+	p.stopTimers()
+	p.gen++
+	p.pressed = true
+	p.consumed = false
+	p.stop = make(chan struct{})
+	gen := p.gen
+
+	for _, lp := range g.longPress {
+		h := lp.handler
+		p.timers = append(p.timers, time.AfterFunc(lp.d, func() {
+			if g.consume(p, gen) {
+				g.invoke(ctx, client, h)
+			}
+		}))
+	}
+
+	for _, hr := range g.holdRepeat {
+		go func(hr timedGesture, stop chan struct{}) {
+			ticker := time.NewTicker(hr.d)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
+					if !g.consume(p, gen) {
+						return
+					}
+					g.invoke(ctx, client, hr.handler)
+				}
+			}
+		}(hr, p.stop)
+	}
+}
+
+func (g *Gestures) up(ctx context.Context, client *Client) error {
+	g.mu.Lock()
+	p, ok := g.presses[sdcontext.Context(ctx)]
+	if !ok || !p.pressed {
+		g.mu.Unlock()
+		return nil
+	}
+	p.pressed = false
+	p.stopTimers()
+	if p.consumed {
+		g.mu.Unlock()
+		return nil
+	}
+
+	if len(g.doubleTap) == 0 {
+		handlers := g.tap
+		g.mu.Unlock()
+		return runGestures(ctx, client, handlers)
+	}
+
+	if p.pendingTap != nil && p.pendingTap.Stop() {
+		p.pendingTap = nil
+		handlers := g.doubleTap
+		g.mu.Unlock()
+		return runGestures(ctx, client, handlers)
+	}
+
+	handlers := g.tap
+	var timer *time.Timer
+	timer = time.AfterFunc(g.window, func() {
+		g.mu.Lock()
+		// a later tap may have replaced the timer while this one waited for the lock
+		if p.pendingTap == timer {
+			p.pendingTap = nil
+		}
+		g.mu.Unlock()
+		for _, h := range handlers {
+			g.invoke(ctx, client, h)
+		}
+	})
+	p.pendingTap = timer
+	g.mu.Unlock()
+	return nil
+}
+
+func (g *Gestures) cancel(ctx context.Context) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p, ok := g.presses[sdcontext.Context(ctx)]
+	if !ok {
+		return
+	}
+	p.stopTimers()
+	if p.pendingTap != nil {
+		p.pendingTap.Stop()
+	}
+	delete(g.presses, sdcontext.Context(ctx))
+}
+
+// consume marks the press as handled by a timed gesture, if it is still held.
+func (g *Gestures) consume(p *press, gen int) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !p.pressed || p.gen != gen {
+		return false
+	}
+	p.consumed = true
+	return true
+}
+
+func (g *Gestures) invoke(ctx context.Context, client *Client, h GestureHandler) {
+	if err := h(ctx, client); err != nil {
+		msg := fmt.Sprintf("Error in gesture handler: %s", err)
+		client.LogMessage(ctx, msg)
+	}
+}
+
+func runGestures(ctx context.Context, client *Client, handlers []GestureHandler) error {
+	var lastErr error
+	for _, h := range handlers {
+		if err := h(ctx, client); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// stopTimers must be called with Gestures.mu held.
+func (p *press) stopTimers() {
+	for _, t := range p.timers {
+		t.Stop()
+	}
+	p.timers = nil
+	if p.stop != nil {
+		close(p.stop)
+		p.stop = nil
+	}
+}
@@ -0,0 +1,152 @@
+package streamdeck
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	sdcontext "github.com/FlowingSPDG/streamdeck/context"
+)
+
+type gestureRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *gestureRecorder) handler(name string) GestureHandler {
+	return func(ctx context.Context, client *Client) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.events = append(r.events, name)
+		return nil
+	}
+}
+
+func (r *gestureRecorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.events...)
+}
+
+func fire(t *testing.T, action *Action, client *Client, eventName string) {
+	t.Helper()
+	ctx := sdcontext.WithContext(context.Background(), "ctx1")
+	eh, ok := action.handlers.m.Load(eventName)
+	if !ok {
+		t.Fatalf("no handler for %s", eventName)
+	}
+	eh.Execute(ctx, client, Event{Event: eventName, Context: "ctx1"})
+}
+
+func TestGesturesTap(t *testing.T) {
+	client := NewClient(context.Background(), RegistrationParams{})
+	action := newAction("dev.example.gesture")
+	g := NewKeyGestures(action)
+	r := &gestureRecorder{}
+	g.OnTap(r.handler("tap"))
+	g.OnLongPress(50*time.Millisecond, r.handler("long"))
+
+	fire(t, action, client, KeyDown)
+	fire(t, action, client, KeyUp)
+
+	if got := r.get(); len(got) != 1 || got[0] != "tap" {
+		t.Errorf("events = %v, want [tap]", got)
+	}
+}
+
+func TestGesturesDoubleTap(t *testing.T) {
+	client := NewClient(context.Background(), RegistrationParams{})
+	action := newAction("dev.example.gesture")
+	g := NewKeyGestures(action)
+	g.SetDoubleTapWindow(50 * time.Millisecond)
+	r := &gestureRecorder{}
+	g.OnTap(r.handler("tap"))
+	g.OnDoubleTap(r.handler("double"))
+
+	fire(t, action, client, KeyDown)
+	fire(t, action, client, KeyUp)
+	fire(t, action, client, KeyDown)
+	fire(t, action, client, KeyUp)
+	time.Sleep(100 * time.Millisecond)
+
+	if got := r.get(); len(got) != 1 || got[0] != "double" {
+		t.Errorf("events = %v, want [double]", got)
+	}
+
+	fire(t, action, client, KeyDown)
+	fire(t, action, client, KeyUp)
+	time.Sleep(100 * time.Millisecond)
+
+	if got := r.get(); len(got) != 2 || got[1] != "tap" {
+		t.Errorf("events = %v, want [double tap]", got)
+	}
+}
+
+func TestGesturesLongPressAndHoldRepeat(t *testing.T) {
+	client := NewClient(context.Background(), RegistrationParams{})
+	action := newAction("dev.example.gesture")
+	g := NewDialGestures(action)
+	r := &gestureRecorder{}
+	g.OnTap(r.handler("tap"))
+	g.OnLongPress(20*time.Millisecond, r.handler("long"))
+	g.OnHoldRepeat(30*time.Millisecond, r.handler("repeat"))
+
+	fire(t, action, client, DialDown)
+	time.Sleep(100 * time.Millisecond)
+	fire(t, action, client, DialUp)
+
+	got := r.get()
+	if len(got) < 3 || got[0] != "long" {
+		t.Fatalf("events = %v, want long followed by repeats", got)
+	}
+	for _, e := range got {
+		if e == "tap" {
+			t.Errorf("long press should not be reported as tap: %v", got)
+		}
+	}
+
+	n := len(got)
+	time.Sleep(60 * time.Millisecond)
+	if len(r.get()) != n {
+		t.Errorf("hold repeat continued after release: %v", r.get())
+	}
+}
+
+func TestGesturesNonPositiveDurations(t *testing.T) {
+	client := NewClient(context.Background(), RegistrationParams{})
+	action := newAction("dev.example.gesture")
+	g := NewKeyGestures(action)
+	r := &gestureRecorder{}
+	g.OnTap(r.handler("tap"))
+	g.OnLongPress(0, r.handler("long"))
+	g.OnHoldRepeat(-time.Second, r.handler("repeat"))
+
+	if g.longPress[0].d != DefaultLongPressThreshold || g.holdRepeat[0].d != DefaultHoldRepeatInterval {
+		t.Errorf("durations = %v, %v, want the defaults", g.longPress[0].d, g.holdRepeat[0].d)
+	}
+
+	// time.NewTicker panics on non-positive intervals
+	fire(t, action, client, KeyDown)
+	fire(t, action, client, KeyUp)
+
+	if got := r.get(); len(got) != 1 || got[0] != "tap" {
+		t.Errorf("events = %v, want [tap]", got)
+	}
+}
+
+func TestGesturesCancelledOnWillDisappear(t *testing.T) {
+	client := NewClient(context.Background(), RegistrationParams{})
+	action := newAction("dev.example.gesture")
+	g := NewKeyGestures(action)
+	r := &gestureRecorder{}
+	g.OnLongPress(20*time.Millisecond, r.handler("long"))
+
+	fire(t, action, client, KeyDown)
+	fire(t, action, client, WillDisappear)
+	time.Sleep(50 * time.Millisecond)
+
+	if got := r.get(); len(got) != 0 {
+		t.Errorf("events = %v, want none", got)
+	}
+}
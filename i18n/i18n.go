@@ -0,0 +1,185 @@
+// Package i18n provides localized strings for plugins, from the <lang>.json files the Stream Deck software uses to localize manifests.
+// refer to https://docs.elgato.com/streamdeck/sdk/guides/localization
+//
+// A localization file holds the translated manifest fields, keyed by action UUID for actions,
+// and the strings of the plugin under "Localization":
+//
+//	{
+//	  "Name": "Compteur",
+//	  "com.example.counter.action": {"Name": "Compteur", "Tooltip": "Compte les appuis"},
+//	  "Localization": {"Reset": "Réinitialiser", "Count": "%d appuis"}
+//	}
+package i18n
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/FlowingSPDG/streamdeck"
+	"golang.org/x/xerrors"
+)
+
+// DefaultLanguage language used when a string is missing from the language of the Stream Deck software.
+const DefaultLanguage = "en"
+
+// ErrNoLocalization no localization file was found.
+var ErrNoLocalization = errors.New("no localization file")
+
+// filePattern names of localization files, such as en.json or zh_CN.json.
+var filePattern = regexp.MustCompile(`^([a-z]{2})([_-][A-Za-z]{2,4})?\.json$`)
+
+// File localization file of a language.
+type File struct {
+	// Manifest translated manifest fields, such as Name and Description, and objects of the fields of actions keyed by action UUID.
+	Manifest map[string]json.RawMessage
+	// Localization strings of the plugin, keyed by their text in the default language or by an identifier.
+	Localization map[string]string
+}
+
+// Localizer Localized strings of a language, falling back to the base language (zh for zh_CN) and then to the default language.
+type Localizer struct {
+	language string
+	files    map[string]*File
+	chain    []string
+}
+
+// Load Load the localization files at the root of fsys, e.g. os.DirFS of the .sdPlugin directory, for the language.
+// fallback is the language used for missing strings, DefaultLanguage when empty.
+func Load(fsys fs.FS, language string, fallback ...string) (*Localizer, error) {
+	files := map[string]*File{}
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read localization files: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || !filePattern.MatchString(e.Name()) {
+			continue
+		}
+		f, err := readFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		files[normalize(strings.TrimSuffix(e.Name(), path.Ext(e.Name())))] = f
+	}
+	if len(files) == 0 {
+		return nil, ErrNoLocalization
+	}
+	return New(files, language, fallback...), nil
+}
+
+// LoadForInfo Load the localization files for the language of the Stream Deck software, from the registration info.
+func LoadForInfo(fsys fs.FS, info streamdeck.Info, fallback ...string) (*Localizer, error) {
+	return Load(fsys, info.Application.Language, fallback...)
+}
+
+// New Get a localizer of the files, keyed by language.
+func New(files map[string]*File, language string, fallback ...string) *Localizer {
+	l := &Localizer{files: map[string]*File{}}
+	for lang, f := range files {
+		l.files[normalize(lang)] = f
+	}
+
+	def := DefaultLanguage
+	if len(fallback) > 0 && fallback[0] != "" {
+		def = fallback[0]
+	}
+	language, def = normalize(language), normalize(def)
+	base, _, _ := strings.Cut(language, "_")
+	for _, lang := range []string{language, base, def} {
+		if _, ok := l.files[lang]; ok && !slices.Contains(l.chain, lang) {
+			l.chain = append(l.chain, lang)
+		}
+	}
+	if len(l.chain) > 0 {
+		l.language = l.chain[0]
+	}
+	return l
+}
+
+// Language Get the language strings are looked up in first, empty if there is no file for the language or the fallback.
+func (l *Localizer) Language() string {
+	return l.language
+}
+
+// T Get the localized string of key, formatted with fmt.Sprintf when args are given. The key itself is used when it is not localized.
+func (l *Localizer) T(key string, args ...any) string {
+	s := key
+	for _, lang := range l.chain {
+		if v, ok := l.files[lang].Localization[key]; ok {
+			s = v
+			break
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(s, args...)
+	}
+	return s
+}
+
+// Has Check if key is localized in the language or its fallbacks.
+func (l *Localizer) Has(key string) bool {
+	for _, lang := range l.chain {
+		if _, ok := l.files[lang].Localization[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Action Get the localized field of the action in the manifest, such as "Name" or "Tooltip", or def when it is not localized.
+func (l *Localizer) Action(uuid, field, def string) string {
+	for _, lang := range l.chain {
+		raw, ok := l.files[lang].Manifest[uuid]
+		if !ok {
+			continue
+		}
+		// entries also hold non-string fields, such as the States array and the Encoder object
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			continue
+		}
+		var v string
+		if err := json.Unmarshal(fields[field], &v); err == nil {
+			return v
+		}
+	}
+	return def
+}
+
+func readFile(fsys fs.FS, name string) (*File, error) {
+	b, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	// localization files are edited by hand, strip the UTF-8 BOM some editors add
+	b = bytes.TrimPrefix(b, []byte("\xef\xbb\xbf"))
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, xerrors.Errorf("failed to parse %s: %w", name, err)
+	}
+	f := &File{Manifest: raw, Localization: map[string]string{}}
+	if loc, ok := raw["Localization"]; ok {
+		if err := json.Unmarshal(loc, &f.Localization); err != nil {
+			return nil, xerrors.Errorf("failed to parse Localization of %s: %w", name, err)
+		}
+		delete(raw, "Localization")
+	}
+	return f, nil
+}
+
+// normalize normalizes language codes to the form of the Stream Deck software, e.g. zh-cn to zh_CN.
+func normalize(lang string) string {
+	base, region, ok := strings.Cut(strings.ReplaceAll(lang, "-", "_"), "_")
+	if !ok {
+		return strings.ToLower(base)
+	}
+	return strings.ToLower(base) + "_" + strings.ToUpper(region)
+}
@@ -0,0 +1,102 @@
+package i18n
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/FlowingSPDG/streamdeck"
+)
+
+var testFS = fstest.MapFS{
+	"manifest.json": {Data: []byte(`{"Name": "Counter"}`)},
+	"en.json": {Data: []byte(`{
+		"Name": "Counter",
+		"com.example.counter.action": {"Name": "Counter", "Tooltip": "Count presses"},
+		"Localization": {"Reset": "Reset", "Count": "%d presses", "Only in English": "Only in English"}
+	}`)},
+	"fr.json": {Data: []byte("\xef\xbb\xbf" + `{
+		"com.example.counter.action": {"Name": "Compteur"},
+		"Localization": {"Reset": "Réinitialiser", "Count": "%d appuis"}
+	}`)},
+	"zh_CN.json": {Data: []byte(`{"Localization": {"Reset": "重置"}}`)},
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		language string
+		want     string
+		key      string
+		args     []any
+		result   string
+	}{
+		{language: "fr", want: "fr", key: "Reset", result: "Réinitialiser"},
+		{language: "fr_CA", want: "fr", key: "Count", args: []any{3}, result: "3 appuis"},
+		{language: "fr", want: "fr", key: "Only in English", result: "Only in English"},
+		{language: "zh-cn", want: "zh_CN", key: "Reset", result: "重置"},
+		{language: "ja", want: "en", key: "Count", args: []any{1}, result: "1 presses"},
+		{language: "fr", want: "fr", key: "Not localized", result: "Not localized"},
+	}
+	for _, tt := range tests {
+		l, err := LoadForInfo(testFS, streamdeck.Info{Application: streamdeck.Application{Language: tt.language}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l.Language() != tt.want {
+			t.Errorf("%s: Language() = %q, want %q", tt.language, l.Language(), tt.want)
+		}
+		if got := l.T(tt.key, tt.args...); got != tt.result {
+			t.Errorf("%s: T(%q) = %q, want %q", tt.language, tt.key, got, tt.result)
+		}
+	}
+}
+
+func TestAction(t *testing.T) {
+	l, err := Load(testFS, "fr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := l.Action("com.example.counter.action", "Name", ""); got != "Compteur" {
+		t.Errorf("Name = %q, want Compteur", got)
+	}
+	if got := l.Action("com.example.counter.action", "Tooltip", ""); got != "Count presses" {
+		t.Errorf("Tooltip = %q, want the fallback", got)
+	}
+	if got := l.Action("com.example.other", "Name", "Other"); got != "Other" {
+		t.Errorf("Name = %q, want the default", got)
+	}
+	if !l.Has("Only in English") || l.Has("Not localized") {
+		t.Error("Has() does not follow the fallback")
+	}
+}
+
+func TestActionStates(t *testing.T) {
+	l, err := Load(fstest.MapFS{
+		"fr.json": {Data: []byte(`{
+			"com.x.a": {"Name": "Compteur", "States": [{"Name": "Un"}], "Encoder": {"TriggerDescription": {"Push": "Remettre"}}}
+		}`)},
+	}, "fr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := l.Action("com.x.a", "Name", "DEFAULT"); got != "Compteur" {
+		t.Errorf("Name = %q, want Compteur", got)
+	}
+	if got := l.Action("com.x.a", "States", "DEFAULT"); got != "DEFAULT" {
+		t.Errorf("States = %q, want the default", got)
+	}
+}
+
+func TestLoadFallback(t *testing.T) {
+	l, err := Load(testFS, "ja", "fr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := l.T("Reset"); got != "Réinitialiser" {
+		t.Errorf("T(Reset) = %q with fallback fr", got)
+	}
+
+	if _, err := Load(fstest.MapFS{"manifest.json": {}}, "en"); !errors.Is(err, ErrNoLocalization) {
+		t.Errorf("err = %v, want ErrNoLocalization", err)
+	}
+}
@@ -0,0 +1,310 @@
+package streamdeck
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"image"
+	_ "image/jpeg" // register JPEG decoder for image.Decode
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/puzpuzpuz/xsync/v3"
+	"golang.org/x/xerrors"
+)
+
+var (
+	imageMIMETypes = map[string]string{
+		".png":  "image/png",
+		".jpg":  "image/jpeg",
+		".jpeg": "image/jpeg",
+		".svg":  "image/svg+xml",
+	}
+	imageExtensions = []string{".png", ".svg", ".jpg", ".jpeg"}
+	pixelRatioRe    = regexp.MustCompile(`@(\d+)[xX]$`)
+)
+
+// ImageCache Cache of images pre-encoded as base64 data URIs for each device pixel ratio.
+// Images are loaded from a fs.FS, so plugins can embed their images folder with "//go:embed images".
+// Like the manifest, names omit the extension and "@2x" suffix, e.g. "images/on" loads "images/on.png" and "images/on@2x.png".
+type ImageCache struct {
+	fsys   fs.FS
+	ratios []int
+	m      *xsync.MapOf[string, map[int]string]
+}
+
+// NewImageCache Get new image cache loading images from fsys. Images are encoded for each ratio, 1 and 2 by default.
+func NewImageCache(fsys fs.FS, ratios ...int) *ImageCache {
+	if len(ratios) == 0 {
+		ratios = []int{1, 2}
+	}
+	return &ImageCache{
+		fsys:   fsys,
+		ratios: ratios,
+		m:      xsync.NewMapOf[string, map[int]string](),
+	}
+}
+
+// Load Load an image from the file system. Missing pixel ratio variants are scaled from an available one.
+func (c *ImageCache) Load(name string) error {
+	if c.fsys == nil {
+		return xerrors.Errorf("no file system to load %q from: %w", name, ErrImageNotFound)
+	}
+
+	// raw file contents for each available pixel ratio
+	files := map[int][]byte{}
+	ext := ""
+	paths := c.imageFiles(name)
+	for _, e := range imageExtensions {
+		for _, ratio := range c.ratios {
+			p, ok := paths[e][ratio]
+			if !ok {
+				continue
+			}
+			b, err := fs.ReadFile(c.fsys, p)
+			if err != nil {
+				continue
+			}
+			files[ratio] = b
+		}
+		if len(files) > 0 {
+			ext = e
+			break
+		}
+	}
+	if len(files) == 0 {
+		return xerrors.Errorf("%q: %w", name, ErrImageNotFound)
+	}
+
+	mime := imageMIMETypes[ext]
+	encoded := map[int]string{}
+	for ratio, b := range files {
+		encoded[ratio] = dataURI(mime, b)
+	}
+
+	if len(encoded) < len(c.ratios) {
+		if ext == ".svg" {
+			// vector images look the same at every ratio
+			for _, ratio := range c.ratios {
+				if _, ok := encoded[ratio]; !ok {
+					encoded[ratio] = anyValue(encoded)
+				}
+			}
+		} else if err := c.scaleMissing(encoded, files); err != nil {
+			return xerrors.Errorf("failed to scale %q: %w", name, err)
+		}
+	}
+
+	c.m.Store(name, encoded)
+	return nil
+}
+
+// LoadAll Load every image below dir. Use "." for the whole file system.
+func (c *ImageCache) LoadAll(dir string) error {
+	names := map[string]struct{}{}
+	err := fs.WalkDir(c.fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		ext := strings.ToLower(path.Ext(p))
+		if _, ok := imageMIMETypes[ext]; !ok {
+			return nil
+		}
+		names[pixelRatioRe.ReplaceAllString(strings.TrimSuffix(p, path.Ext(p)), "")] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return xerrors.Errorf("failed to walk %q: %w", dir, err)
+	}
+
+	for name := range names {
+		if err := c.Load(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Add Store an image under name, encoded for each pixel ratio. img is treated as the ratio 1 image.
+func (c *ImageCache) Add(name string, img image.Image) error {
+	encoded := map[int]string{}
+	for _, ratio := range c.ratios {
+		b := img.Bounds()
+		s, err := Image(scaleImage(img, b.Dx()*ratio, b.Dy()*ratio))
+		if err != nil {
+			return xerrors.Errorf("failed to encode %q: %w", name, err)
+		}
+		encoded[ratio] = s
+	}
+	c.m.Store(name, encoded)
+	return nil
+}
+
+// AddImage Store an image keyed by a hash of its content, and return the key.
+func (c *ImageCache) AddImage(img image.Image) (string, error) {
+	s, err := Image(img)
+	if err != nil {
+		return "", xerrors.Errorf("failed to encode image: %w", err)
+	}
+	sum := sha256.Sum256([]byte(s))
+	key := hex.EncodeToString(sum[:])
+
+	if _, ok := c.m.Load(key); ok {
+		return key, nil
+	}
+	if err := c.Add(key, img); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// Get Get the data URI of an image for the pixel ratio. The closest available ratio is used when the exact one is missing.
+// Images not cached yet are loaded from the file system.
+func (c *ImageCache) Get(name string, ratio int) (string, error) {
+	encoded, ok := c.m.Load(name)
+	if !ok {
+		if err := c.Load(name); err != nil {
+			return "", err
+		}
+		encoded, _ = c.m.Load(name)
+	}
+
+	if s, ok := encoded[ratio]; ok {
+		return s, nil
+	}
+
+	best := -1
+	for r := range encoded {
+		if best == -1 || abs(r-ratio) < abs(best-ratio) || (abs(r-ratio) == abs(best-ratio) && r > best) {
+			best = r
+		}
+	}
+	return encoded[best], nil
+}
+
+// Names Get the names of all cached images.
+func (c *ImageCache) Names() []string {
+	names := make([]string, 0, c.m.Size())
+	c.m.Range(func(key string, _ map[int]string) bool {
+		names = append(names, key)
+		return true
+	})
+	sort.Strings(names)
+	return names
+}
+
+// SetCachedImage Change the image displayed by an instance of an action to an image from the cache, encoded for the device pixel ratio.
+func (client *Client) SetCachedImage(ctx context.Context, cache *ImageCache, name string, target Target, state ...int) error {
+	ratio := client.params.Info.DevicePixelRatio
+	if ratio < 1 {
+		ratio = 1
+	}
+	s, err := cache.Get(name, ratio)
+	if err != nil {
+		return err
+	}
+	return client.SetImage(ctx, s, target, state...)
+}
+
+func (c *ImageCache) scaleMissing(encoded map[int]string, files map[int][]byte) error {
+	// scale from the highest resolution available
+	src := 0
+	for ratio := range files {
+		src = max(src, ratio)
+	}
+	img, _, err := image.Decode(bytes.NewReader(files[src]))
+	if err != nil {
+		return err
+	}
+
+	b := img.Bounds()
+	for _, ratio := range c.ratios {
+		if _, ok := encoded[ratio]; ok {
+			continue
+		}
+		s, err := Image(scaleImage(img, b.Dx()*ratio/src, b.Dy()*ratio/src))
+		if err != nil {
+			return err
+		}
+		encoded[ratio] = s
+	}
+	return nil
+}
+
+// imageFiles paths of the files of the image name, by lowercase extension and pixel ratio.
+// Extensions are matched case-insensitively, e.g. "images/on" finds "images/on.PNG".
+func (c *ImageCache) imageFiles(name string) map[string]map[int]string {
+	paths := map[string]map[int]string{}
+	entries, err := fs.ReadDir(c.fsys, path.Dir(name))
+	if err != nil {
+		return paths
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		ext := strings.ToLower(path.Ext(e.Name()))
+		if _, ok := imageMIMETypes[ext]; !ok {
+			continue
+		}
+		base := strings.TrimSuffix(e.Name(), path.Ext(e.Name()))
+		ratio := 1
+		if m := pixelRatioRe.FindStringSubmatch(base); m != nil {
+			ratio, _ = strconv.Atoi(m[1])
+			base = strings.TrimSuffix(base, m[0])
+		}
+		if base != path.Base(name) {
+			continue
+		}
+		if paths[ext] == nil {
+			paths[ext] = map[int]string{}
+		}
+		paths[ext][ratio] = path.Join(path.Dir(name), e.Name())
+	}
+	return paths
+}
+
+func dataURI(mime string, b []byte) string {
+	return "data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(b)
+}
+
+// scaleImage resizes src to w x h using nearest neighbour sampling.
+func scaleImage(src image.Image, w, h int) image.Image {
+	b := src.Bounds()
+	if b.Dx() == w && b.Dy() == h {
+		return src
+	}
+	w, h = max(w, 1), max(h, 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		sy := b.Min.Y + y*b.Dy()/h
+		for x := 0; x < w; x++ {
+			dst.Set(x, y, src.At(b.Min.X+x*b.Dx()/w, sy))
+		}
+	}
+	return dst
+}
+
+func anyValue(m map[int]string) string {
+	for _, v := range m {
+		return v
+	}
+	return ""
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
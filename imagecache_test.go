@@ -0,0 +1,111 @@
+package streamdeck
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		t.Fatalf("failed to encode PNG: %v", err)
+	}
+	return b.Bytes()
+}
+
+func TestImageCache(t *testing.T) {
+	fsys := fstest.MapFS{
+		"images/on.png":     {Data: encodePNG(t, solid(color.White))},
+		"images/on@2x.png":  {Data: encodePNG(t, image.NewRGBA(image.Rect(0, 0, 8, 8)))},
+		"images/off.png":    {Data: encodePNG(t, solid(color.Black))},
+		"images/logo.svg":   {Data: []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`)},
+		"images/readme.txt": {Data: []byte("not an image")},
+	}
+
+	cache := NewImageCache(fsys)
+	if err := cache.LoadAll("images"); err != nil {
+		t.Fatalf("LoadAll() error = %v", err)
+	}
+
+	if got, want := strings.Join(cache.Names(), ","), "images/logo,images/off,images/on"; got != want {
+		t.Errorf("Names() = %s, want %s", got, want)
+	}
+
+	on1, err := cache.Get("images/on", 1)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	on2, err := cache.Get("images/on", 2)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if on1 == on2 {
+		t.Error("ratio 1 and 2 should use different files")
+	}
+
+	off2, err := cache.Get("images/off", 2)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	off1, _ := cache.Get("images/off", 1)
+	if off1 == off2 {
+		t.Error("ratio 2 should be scaled from ratio 1")
+	}
+
+	logo, err := cache.Get("images/logo", 2)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !strings.HasPrefix(logo, "data:image/svg+xml;base64,") {
+		t.Errorf("unexpected SVG data URI %q", logo)
+	}
+
+	if _, err := cache.Get("images/missing", 1); !errors.Is(err, ErrImageNotFound) {
+		t.Errorf("Get() error = %v, want ErrImageNotFound", err)
+	}
+}
+
+func TestImageCacheUppercaseExtension(t *testing.T) {
+	fsys := fstest.MapFS{
+		"images/ICON.PNG":    {Data: encodePNG(t, solid(color.White))},
+		"images/ICON@2X.PNG": {Data: encodePNG(t, image.NewRGBA(image.Rect(0, 0, 8, 8)))},
+		"images/foo.SVG":     {Data: []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`)},
+	}
+
+	cache := NewImageCache(fsys)
+	if err := cache.LoadAll("images"); err != nil {
+		t.Fatalf("LoadAll() error = %v", err)
+	}
+	if got, want := strings.Join(cache.Names(), ","), "images/ICON,images/foo"; got != want {
+		t.Errorf("Names() = %s, want %s", got, want)
+	}
+	foo, err := cache.Get("images/foo", 1)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !strings.HasPrefix(foo, "data:image/svg+xml;base64,") {
+		t.Errorf("unexpected SVG data URI %q", foo)
+	}
+}
+
+func TestImageCacheAddImage(t *testing.T) {
+	cache := NewImageCache(nil)
+	k1, err := cache.AddImage(solid(color.White))
+	if err != nil {
+		t.Fatalf("AddImage() error = %v", err)
+	}
+	k2, _ := cache.AddImage(solid(color.White))
+	if k1 != k2 {
+		t.Errorf("identical images got different keys %s and %s", k1, k2)
+	}
+	if _, err := cache.Get(k1, 2); err != nil {
+		t.Errorf("Get() error = %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package streamdeck
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	sdcontext "github.com/FlowingSPDG/streamdeck/context"
+)
+
+// trackPropertyInspector keeps the contexts whose property inspector is open, from the events received.
+func (client *Client) trackPropertyInspector(event Event) {
+	switch event.Event {
+	case PropertyInspectorDidAppear:
+		client.inspectors.Store(event.Context, struct{}{})
+	case PropertyInspectorDidDisappear, WillDisappear:
+		client.inspectors.Delete(event.Context)
+	}
+}
+
+// PropertyInspectorOpen Check if the property inspector of the action instance of ctx is open.
+func (client *Client) PropertyInspectorOpen(ctx context.Context) bool {
+	_, ok := client.inspectors.Load(sdcontext.Context(ctx))
+	return ok
+}
+
+// DefaultPushInterval Interval of PushToPropertyInspector when the given one is not positive.
+const DefaultPushInterval = time.Second
+
+// PropertyInspectorPush Periodically sends data to the property inspectors of an action while they are open.
+type PropertyInspectorPush struct {
+	interval time.Duration
+	data     func(ctx context.Context) (any, error)
+
+	mu      sync.Mutex
+	stopped bool
+	cancels map[string]context.CancelFunc
+}
+
+// PushToPropertyInspector Send the data returned by data to the property inspector of every instance of the action,
+// when it appears then every interval until it disappears. Nothing is sent while no property inspector is open.
+// DefaultPushInterval is used when interval is not positive.
+func (client *Client) PushToPropertyInspector(action *Action, interval time.Duration, data func(ctx context.Context) (any, error)) *PropertyInspectorPush {
+	if interval <= 0 {
+		interval = DefaultPushInterval
+	}
+	p := &PropertyInspectorPush{
+		interval: interval,
+		data:     data,
+		cancels:  map[string]context.CancelFunc{},
+	}
+	action.RegisterHandler(PropertyInspectorDidAppear, func(ctx context.Context, client *Client, event Event) error {
+		p.start(ctx, client)
+		return nil
+	})
+	action.RegisterHandler(PropertyInspectorDidDisappear, func(ctx context.Context, client *Client, event Event) error {
+		p.cancel(sdcontext.Context(ctx))
+		return nil
+	})
+	action.RegisterHandler(WillDisappear, func(ctx context.Context, client *Client, event Event) error {
+		p.cancel(sdcontext.Context(ctx))
+		return nil
+	})
+	return p
+}
+
+func (p *PropertyInspectorPush) start(ctx context.Context, client *Client) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return
+	}
+	if cancel, ok := p.cancels[sdcontext.Context(ctx)]; ok {
+		cancel()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	p.cancels[sdcontext.Context(ctx)] = cancel
+
+	go func() {
+		t := time.NewTicker(p.interval)
+		defer t.Stop()
+		for {
+			// the property inspector may have disappeared while the data was computed
+			if client.PropertyInspectorOpen(ctx) {
+				if err := p.push(ctx, client); err != nil {
+					logger.Printf("failed to push to the property inspector of %s: %v\n", sdcontext.Context(ctx), err)
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}()
+}
+
+func (p *PropertyInspectorPush) push(ctx context.Context, client *Client) error {
+	v, err := p.data(ctx)
+	if err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return client.SendToPropertyInspector(ctx, v)
+}
+
+func (p *PropertyInspectorPush) cancel(contextID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if cancel, ok := p.cancels[contextID]; ok {
+		cancel()
+		delete(p.cancels, contextID)
+	}
+}
+
+// Stop Stop pushing to every property inspector.
+func (p *PropertyInspectorPush) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stopped = true
+	for contextID, cancel := range p.cancels {
+		cancel()
+		delete(p.cancels, contextID)
+	}
+}
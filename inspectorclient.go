@@ -0,0 +1,242 @@
+package streamdeck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	sdcontext "github.com/FlowingSPDG/streamdeck/context"
+	"github.com/coder/websocket"
+	"github.com/puzpuzpuz/xsync/v3"
+	"golang.org/x/xerrors"
+)
+
+// PropertyInspectorParams Params the Stream Deck software passes to connectElgatoStreamDeckSocket of a property inspector.
+type PropertyInspectorParams struct {
+	Port int
+	// PropertyInspectorUUID UUID of the property inspector, which is also the context of its action instance.
+	PropertyInspectorUUID string
+	RegisterEvent         string
+	Info                  Info
+	ActionInfo            ActionInfo[json.RawMessage]
+}
+
+// ParsePropertyInspectorParams Parse the arguments of connectElgatoStreamDeckSocket(inPort, inPropertyInspectorUUID, inRegisterEvent, inInfo, inActionInfo).
+func ParsePropertyInspectorParams(port, uuid, registerEvent, info, actionInfo string) (PropertyInspectorParams, error) {
+	ret := PropertyInspectorParams{PropertyInspectorUUID: uuid, RegisterEvent: registerEvent}
+
+	// the arguments are those of the property inspector, not the -port, -pluginUUID... flags of the plugin
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return ret, xerrors.Errorf("%w: inPort: %v", ErrInvalidPropertyInspectorParams, err)
+	}
+	ret.Port = p
+	if uuid == "" {
+		return ret, xerrors.Errorf("%w: missing inPropertyInspectorUUID", ErrInvalidPropertyInspectorParams)
+	}
+	if registerEvent == "" {
+		return ret, xerrors.Errorf("%w: missing inRegisterEvent", ErrInvalidPropertyInspectorParams)
+	}
+	if info == "" {
+		return ret, xerrors.Errorf("%w: missing inInfo", ErrInvalidPropertyInspectorParams)
+	}
+	if err := json.Unmarshal([]byte(info), &ret.Info); err != nil {
+		return ret, xerrors.Errorf("%w: inInfo: %v", ErrInvalidPropertyInspectorParams, err)
+	}
+	if actionInfo != "" {
+		if err := json.Unmarshal([]byte(actionInfo), &ret.ActionInfo); err != nil {
+			return ret, xerrors.Errorf("%w: inActionInfo: %v", ErrInvalidPropertyInspectorParams, err)
+		}
+	}
+	return ret, nil
+}
+
+// PropertyInspectorHandler Event handler func of a property inspector client.
+type PropertyInspectorHandler func(ctx context.Context, client *PropertyInspectorClient, event Event) error
+
+// PropertyInspectorClient Client acting as the property inspector of an action instance, e.g. from Go compiled to WebAssembly or in integration tests.
+// It registers with registerPropertyInspector, sends sendToPlugin and settings messages for its instance, and receives sendToPropertyInspector and settings events.
+type PropertyInspectorClient struct {
+	params    PropertyInspectorParams
+	c         *websocket.Conn
+	handlers  *xsync.MapOf[string, []PropertyInspectorHandler]
+	done      chan struct{}
+	sendMutex *sync.Mutex
+}
+
+// NewPropertyInspectorClient Get new property inspector client from specified params.
+func NewPropertyInspectorClient(params PropertyInspectorParams) *PropertyInspectorClient {
+	return &PropertyInspectorClient{
+		params:    params,
+		handlers:  xsync.NewMapOf[string, []PropertyInspectorHandler](),
+		done:      make(chan struct{}),
+		sendMutex: &sync.Mutex{},
+	}
+}
+
+// Params Get the params of the client.
+func (client *PropertyInspectorClient) Params() PropertyInspectorParams {
+	return client.params
+}
+
+// RegisterHandler Register event handler to specified event, such as SendToPropertyInspector or DidReceiveSettings.
+func (client *PropertyInspectorClient) RegisterHandler(eventName string, handler PropertyInspectorHandler) {
+	client.handlers.Compute(eventName, func(hs []PropertyInspectorHandler, _ bool) ([]PropertyInspectorHandler, bool) {
+		return append(hs[:len(hs):len(hs)], handler), false
+	})
+}
+
+// OnSendToPropertyInspector registers a type-safe handler of the messages the plugin sends to the property inspector
+func OnSendToPropertyInspector[T any](client *PropertyInspectorClient, handler func(ctx context.Context, client *PropertyInspectorClient, payload T) error) {
+	client.RegisterHandler(SendToPropertyInspector, func(ctx context.Context, client *PropertyInspectorClient, event Event) error {
+		var payload T
+		if err := event.UnmarshalPayload(&payload); err != nil {
+			return xerrors.Errorf("failed to unmarshal %s payload: %w", SendToPropertyInspector, err)
+		}
+		return handler(ctx, client, payload)
+	})
+}
+
+// Run Connect to the Stream Deck software and dispatch events until the connection is closed or ctx is done.
+func (client *PropertyInspectorClient) Run(ctx context.Context) error {
+	u := url.URL{Scheme: "ws", Host: fmt.Sprintf("127.0.0.1:%d", client.params.Port)}
+	c, _, err := websocket.Dial(ctx, u.String(), nil)
+	if err != nil {
+		return xerrors.Errorf("failed to connect to StreamDeck: %w", err)
+	}
+	c.SetReadLimit(-1)
+
+	// register before other messages can be sent on the connection
+	b, err := json.Marshal(Event{UUID: client.params.PropertyInspectorUUID, Event: client.params.RegisterEvent})
+	if err != nil {
+		c.CloseNow()
+		return xerrors.Errorf("%w: %v", ErrJSONMarshal, err)
+	}
+	client.sendMutex.Lock()
+	if err := c.Write(ctx, websocket.MessageText, b); err != nil {
+		client.sendMutex.Unlock()
+		c.CloseNow()
+		return xerrors.Errorf("failed to register with StreamDeck: %w", err)
+	}
+	client.c = c
+	client.sendMutex.Unlock()
+
+	go func() {
+		defer close(client.done)
+		for {
+			_, message, err := c.Read(ctx)
+			if err != nil {
+				logger.Printf("read error: %v\n", err)
+				return
+			}
+
+			event := Event{}
+			if err := json.Unmarshal(message, &event); err != nil {
+				logger.Printf("failed to unmarshal received event: %s\n", string(message))
+				continue
+			}
+			logger.Println("recv: ", string(message))
+
+			handlers, _ := client.handlers.Load(event.Event)
+			for _, handler := range handlers {
+				if err := handler(client.withInstance(ctx), client, event); err != nil {
+					logger.Printf("error in %s handler: %v\n", event.Event, err)
+				}
+			}
+		}
+	}()
+
+	select {
+	case <-client.done:
+		return nil
+	case <-ctx.Done():
+		client.Close()
+		return nil
+	}
+}
+
+// withInstance ctx with the action instance of the property inspector.
+func (client *PropertyInspectorClient) withInstance(ctx context.Context) context.Context {
+	ctx = sdcontext.WithContext(ctx, client.params.PropertyInspectorUUID)
+	ctx = sdcontext.WithAction(ctx, client.params.ActionInfo.Action)
+	return sdcontext.WithDevice(ctx, client.params.ActionInfo.Device)
+}
+
+// event event of the action instance of the property inspector.
+func (client *PropertyInspectorClient) event(name string, payload any) Event {
+	return Event{
+		Event:   name,
+		Action:  client.params.ActionInfo.Action,
+		Context: client.params.PropertyInspectorUUID,
+		Payload: payload,
+	}
+}
+
+func (client *PropertyInspectorClient) send(ctx context.Context, event Event) error {
+	client.sendMutex.Lock()
+	defer client.sendMutex.Unlock()
+
+	if client.c == nil {
+		return xerrors.Errorf("%w: not connected", ErrWriteFailed)
+	}
+	b, err := json.Marshal(event)
+	if err != nil {
+		return xerrors.Errorf("%w: %v", ErrJSONMarshal, err)
+	}
+	if err := client.c.Write(ctx, websocket.MessageText, b); err != nil {
+		return xerrors.Errorf("%w: %v", ErrWriteFailed, err)
+	}
+	return nil
+}
+
+// SendToPlugin Send a payload to the plugin, received by its sendToPlugin handlers for the action instance.
+func (client *PropertyInspectorClient) SendToPlugin(ctx context.Context, payload any) error {
+	return client.send(ctx, client.event(SendToPlugin, payload))
+}
+
+// SetSettings Save data persistently for the action instance.
+func (client *PropertyInspectorClient) SetSettings(ctx context.Context, settings any) error {
+	return client.send(ctx, client.event(SetSettings, settings))
+}
+
+// GetSettings Request the persistent data of the action instance, received with didReceiveSettings.
+func (client *PropertyInspectorClient) GetSettings(ctx context.Context) error {
+	return client.send(ctx, client.event(GetSettings, nil))
+}
+
+// SetGlobalSettings Save data securely and globally for the plugin.
+func (client *PropertyInspectorClient) SetGlobalSettings(ctx context.Context, settings any) error {
+	return client.send(ctx, client.event(SetGlobalSettings, settings))
+}
+
+// GetGlobalSettings Request the global persistent data, received with didReceiveGlobalSettings.
+func (client *PropertyInspectorClient) GetGlobalSettings(ctx context.Context) error {
+	return client.send(ctx, client.event(GetGlobalSettings, nil))
+}
+
+// OpenURL Open an URL in the default browser.
+func (client *PropertyInspectorClient) OpenURL(ctx context.Context, u url.URL) error {
+	return client.send(ctx, client.event(OpenURL, OpenURLPayload{URL: u.String()}))
+}
+
+// Close close client
+func (client *PropertyInspectorClient) Close() error {
+	client.sendMutex.Lock()
+	c := client.c
+	client.sendMutex.Unlock()
+	if c == nil {
+		return nil
+	}
+	if err := c.Close(websocket.StatusNormalClosure, ""); err != nil {
+		return err
+	}
+	select {
+	case <-client.done:
+	case <-time.After(time.Second):
+	}
+	return nil
+}
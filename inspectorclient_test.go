@@ -0,0 +1,93 @@
+package streamdeck_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/FlowingSPDG/streamdeck"
+	sdcontext "github.com/FlowingSPDG/streamdeck/context"
+	"github.com/FlowingSPDG/streamdeck/sdtest"
+)
+
+func TestParsePropertyInspectorParams(t *testing.T) {
+	p, err := streamdeck.ParsePropertyInspectorParams("28196", "PI-UUID", streamdeck.RegisterPropertyInspector,
+		`{"application":{"language":"en","platform":"mac","version":"6.5.0"},"devices":[]}`,
+		`{"action":"com.example.action","context":"PI-UUID","device":"DEVICE","payload":{"settings":{"counter":1},"coordinates":{"column":1,"row":2}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Port != 28196 || p.ActionInfo.Action != "com.example.action" || p.ActionInfo.Payload.Coordinates.Row != 2 || string(p.ActionInfo.Payload.Settings) != `{"counter":1}` {
+		t.Errorf("ParsePropertyInspectorParams() = %+v", p)
+	}
+
+	if _, err := streamdeck.ParsePropertyInspectorParams("port", "PI-UUID", streamdeck.RegisterPropertyInspector, "{}", ""); !errors.Is(err, streamdeck.ErrInvalidPropertyInspectorParams) {
+		t.Errorf("invalid port: err = %v, want ErrInvalidPropertyInspectorParams", err)
+	}
+	if _, err := streamdeck.ParsePropertyInspectorParams("28196", "", streamdeck.RegisterPropertyInspector, "{}", ""); !errors.Is(err, streamdeck.ErrInvalidPropertyInspectorParams) || errors.Is(err, streamdeck.ErrMissingPluginUUIDFlag) {
+		t.Errorf("missing UUID: err = %v, want ErrInvalidPropertyInspectorParams", err)
+	}
+}
+
+func TestPropertyInspectorClient(t *testing.T) {
+	srv := sdtest.NewServer(t)
+	plugin := streamdeck.NewClient(context.Background(), srv.RegistrationParams())
+	streamdeck.OnSendToPlugin(plugin.Action("com.example.action"), func(ctx context.Context, client *streamdeck.Client, payload struct{ Action string }) error {
+		return client.SendToPropertyInspector(ctx, map[string]string{"status": payload.Action + " ok"})
+	})
+	srv.RunClient(plugin)
+
+	inst := sdtest.NewInstance("com.example.action", 0, 0)
+	srv.WillAppear(inst, nil)
+
+	client := streamdeck.NewPropertyInspectorClient(srv.PropertyInspectorParams(inst))
+	received := make(chan string, 1)
+	streamdeck.OnSendToPropertyInspector(client, func(ctx context.Context, client *streamdeck.PropertyInspectorClient, payload struct{ Status string }) error {
+		received <- sdcontext.Context(ctx) + " " + payload.Status
+		return nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go client.Run(ctx)
+	if err := srv.WaitPropertyInspector(inst.Context); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.SendToPlugin(ctx, map[string]string{"action": "reset"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-received:
+		if got != inst.Context+" reset ok" {
+			t.Errorf("received %q", got)
+		}
+	case <-ctx.Done():
+		t.Fatal("sendToPropertyInspector not received")
+	}
+
+	// messages of the property inspector are kept apart from those of the plugin
+	if msgs := srv.InspectorMessages(); len(msgs) != 1 || msgs[0].Event != streamdeck.SendToPlugin || msgs[0].Action != "com.example.action" {
+		t.Errorf("InspectorMessages() = %+v", msgs)
+	}
+	for _, m := range srv.Messages() {
+		if m.Event == streamdeck.SendToPlugin {
+			t.Errorf("Messages() has the sendToPlugin of the property inspector: %+v", m)
+		}
+	}
+}
+
+func TestClientSendToPlugin(t *testing.T) {
+	srv := sdtest.NewServer(t)
+	client := streamdeck.NewClient(context.Background(), srv.RegistrationParams())
+	srv.RunClient(client)
+
+	ctx := sdcontext.WithContext(context.Background(), "CONTEXT")
+	if err := client.SendToPlugin(ctx, "com.example.action", map[string]string{"action": "reset"}); err != nil {
+		t.Fatal(err)
+	}
+	event := srv.Expect(streamdeck.SendToPlugin, "CONTEXT", nil)
+	if event.Action != "com.example.action" {
+		t.Errorf("sendToPlugin = %+v", event)
+	}
+}
@@ -0,0 +1,211 @@
+package streamdeck
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	"golang.org/x/xerrors"
+)
+
+// Size of the touch display canvas of a single encoder.
+const (
+	LayoutCanvasWidth  = 200
+	LayoutCanvasHeight = 100
+
+	layoutMaxZOrder = 700
+)
+
+// ItemType Type of a layout item.
+type ItemType string
+
+const (
+	// ItemText text
+	ItemText ItemType = "text"
+	// ItemPixmap pixmap
+	ItemPixmap ItemType = "pixmap"
+	// ItemBar bar
+	ItemBar ItemType = "bar"
+	// ItemGBar gbar
+	ItemGBar ItemType = "gbar"
+)
+
+// Rect Position and size of a layout item. [x, y, width, height]
+type Rect [4]int
+
+// X x
+func (r Rect) X() int { return r[0] }
+
+// Y y
+func (r Rect) Y() int { return r[1] }
+
+// Width width
+func (r Rect) Width() int { return r[2] }
+
+// Height height
+func (r Rect) Height() int { return r[3] }
+
+func (r Rect) overlaps(o Rect) bool {
+	return r.X() < o.X()+o.Width() && o.X() < r.X()+r.Width() &&
+		r.Y() < o.Y()+o.Height() && o.Y() < r.Y()+r.Height()
+}
+
+// Layout A custom layout of the Stream Deck + touch display, as written in a layout JSON file.
+type Layout struct {
+	ID    string       `json:"id"`
+	Items []LayoutItem `json:"items"`
+}
+
+// LayoutItem An item of a custom layout.
+type LayoutItem struct {
+	Key    string
+	Type   ItemType
+	Rect   Rect
+	ZOrder int
+	// Properties initial properties of the item. *TextItem, *PixmapItem, *BarItem or *GBarItem matching Type.
+	Properties any
+}
+
+// MarshalJSON flattens the properties into the item.
+func (item LayoutItem) MarshalJSON() ([]byte, error) {
+	m := map[string]any{}
+	if item.Properties != nil {
+		b, err := json.Marshal(item.Properties)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &m); err != nil {
+			return nil, err
+		}
+	}
+	m["key"] = item.Key
+	m["type"] = item.Type
+	m["rect"] = item.Rect
+	if item.ZOrder != 0 {
+		m["zOrder"] = item.ZOrder
+	}
+	return json.Marshal(m)
+}
+
+// Validate Check the layout can be loaded by the Stream Deck software.
+func (l Layout) Validate() error {
+	var errs []error
+	if l.ID == "" {
+		errs = append(errs, xerrors.New("layout id is empty"))
+	}
+	if len(l.Items) == 0 {
+		errs = append(errs, xerrors.New("layout has no items"))
+	}
+
+	keys := map[string]struct{}{}
+	for i, item := range l.Items {
+		if item.Key == "" {
+			errs = append(errs, xerrors.Errorf("item %d: key is empty", i))
+		} else if _, ok := keys[item.Key]; ok {
+			errs = append(errs, xerrors.Errorf("item %q: duplicate key", item.Key))
+		}
+		keys[item.Key] = struct{}{}
+
+		switch item.Type {
+		case ItemText, ItemPixmap, ItemBar, ItemGBar:
+		default:
+			errs = append(errs, xerrors.Errorf("item %q: unknown type %q", item.Key, item.Type))
+		}
+
+		r := item.Rect
+		if r.Width() <= 0 || r.Height() <= 0 {
+			errs = append(errs, xerrors.Errorf("item %q: rect %v has no area", item.Key, r))
+		}
+		if r.X() < 0 || r.Y() < 0 || r.X()+r.Width() > LayoutCanvasWidth || r.Y()+r.Height() > LayoutCanvasHeight {
+			errs = append(errs, xerrors.Errorf("item %q: rect %v is outside of the %dx%d canvas", item.Key, r, LayoutCanvasWidth, LayoutCanvasHeight))
+		}
+		if item.ZOrder < 0 || item.ZOrder > layoutMaxZOrder {
+			errs = append(errs, xerrors.Errorf("item %q: zOrder %d is out of range 0-%d", item.Key, item.ZOrder, layoutMaxZOrder))
+		}
+		for _, other := range l.Items[:i] {
+			if other.ZOrder == item.ZOrder && other.Rect.overlaps(r) {
+				errs = append(errs, xerrors.Errorf("item %q: overlaps %q with the same zOrder %d", item.Key, other.Key, item.ZOrder))
+			}
+		}
+
+		var rng *Range
+		switch p := item.Properties.(type) {
+		case *BarItem:
+			rng = p.Range
+		case *GBarItem:
+			rng = p.Range
+		}
+		if rng != nil && rng.Min >= rng.Max {
+			errs = append(errs, xerrors.Errorf("item %q: range min %g must be less than max %g", item.Key, rng.Min, rng.Max))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WriteFile Validate the layout and write it as a layout JSON file.
+func (l Layout) WriteFile(name string) error {
+	if err := l.Validate(); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return xerrors.Errorf("%w: %v", ErrJSONMarshal, err)
+	}
+	return os.WriteFile(name, b, 0o644)
+}
+
+// LayoutBuilder Builder of custom layouts.
+type LayoutBuilder struct {
+	layout Layout
+	zOrder int
+}
+
+// NewLayoutBuilder Get new layout builder for the layout id.
+func NewLayoutBuilder(id string) *LayoutBuilder {
+	return &LayoutBuilder{layout: Layout{ID: id}}
+}
+
+// ZOrder Set the zOrder of items added after this call.
+func (b *LayoutBuilder) ZOrder(z int) *LayoutBuilder {
+	b.zOrder = z
+	return b
+}
+
+// Text Add a text item.
+func (b *LayoutBuilder) Text(key string, rect Rect, props TextItem) *LayoutBuilder {
+	return b.add(key, ItemText, rect, &props)
+}
+
+// Pixmap Add a pixmap item.
+func (b *LayoutBuilder) Pixmap(key string, rect Rect, props PixmapItem) *LayoutBuilder {
+	return b.add(key, ItemPixmap, rect, &props)
+}
+
+// Bar Add a bar item.
+func (b *LayoutBuilder) Bar(key string, rect Rect, props BarItem) *LayoutBuilder {
+	return b.add(key, ItemBar, rect, &props)
+}
+
+// GBar Add a gbar item.
+func (b *LayoutBuilder) GBar(key string, rect Rect, props GBarItem) *LayoutBuilder {
+	return b.add(key, ItemGBar, rect, &props)
+}
+
+func (b *LayoutBuilder) add(key string, typ ItemType, rect Rect, props any) *LayoutBuilder {
+	b.layout.Items = append(b.layout.Items, LayoutItem{
+		Key:        key,
+		Type:       typ,
+		Rect:       rect,
+		ZOrder:     b.zOrder,
+		Properties: props,
+	})
+	return b
+}
+
+// Build Validate and get the layout.
+func (b *LayoutBuilder) Build() (Layout, error) {
+	if err := b.layout.Validate(); err != nil {
+		return Layout{}, err
+	}
+	return b.layout, nil
+}
@@ -0,0 +1,174 @@
+// Package manifest provides the types of the manifest.json file of a Stream Deck plugin, and its validation.
+// refer to https://docs.elgato.com/streamdeck/sdk/references/manifest
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+
+	"golang.org/x/xerrors"
+)
+
+// FileName name of the manifest file in the .sdPlugin directory.
+const FileName = "manifest.json"
+
+// SDKVersion version of the Stream Deck SDK the manifest is written for.
+const SDKVersion = 2
+
+// Controller Controller an action supports.
+type Controller string
+
+const (
+	// Keypad key of the keypad
+	Keypad Controller = "Keypad"
+	// Encoder dial and touch display segment of a Stream Deck +
+	Encoder Controller = "Encoder"
+)
+
+// Platform Operating system a plugin runs on.
+type Platform string
+
+const (
+	// Mac macOS
+	Mac Platform = "mac"
+	// Windows Windows
+	Windows Platform = "windows"
+)
+
+// Manifest manifest.json of a plugin.
+type Manifest struct {
+	Actions               []Action               `json:"Actions"`
+	ApplicationsToMonitor *ApplicationsToMonitor `json:"ApplicationsToMonitor,omitempty"`
+	Author                string                 `json:"Author"`
+	Category              string                 `json:"Category,omitempty"`
+	CategoryIcon          string                 `json:"CategoryIcon,omitempty"`
+	CodePath              string                 `json:"CodePath,omitempty"`
+	CodePathMac           string                 `json:"CodePathMac,omitempty"`
+	CodePathWin           string                 `json:"CodePathWin,omitempty"`
+	Description           string                 `json:"Description"`
+	Icon                  string                 `json:"Icon"`
+	Name                  string                 `json:"Name"`
+	OS                    []OS                   `json:"OS"`
+	Profiles              []Profile              `json:"Profiles,omitempty"`
+	PropertyInspectorPath string                 `json:"PropertyInspectorPath,omitempty"`
+	SDKVersion            int                    `json:"SDKVersion"`
+	Software              Software               `json:"Software"`
+	URL                   string                 `json:"URL,omitempty"`
+	UUID                  string                 `json:"UUID,omitempty"`
+	Version               string                 `json:"Version"`
+}
+
+// Action An action of the plugin.
+type Action struct {
+	Controllers             []Controller   `json:"Controllers,omitempty"`
+	DisableAutomaticStates  bool           `json:"DisableAutomaticStates,omitempty"`
+	DisableCaching          bool           `json:"DisableCaching,omitempty"`
+	Encoder                 *ActionEncoder `json:"Encoder,omitempty"`
+	Icon                    string         `json:"Icon"`
+	Name                    string         `json:"Name"`
+	PropertyInspectorPath   string         `json:"PropertyInspectorPath,omitempty"`
+	States                  []State        `json:"States"`
+	SupportedInMultiActions *bool          `json:"SupportedInMultiActions,omitempty"`
+	Tooltip                 string         `json:"Tooltip,omitempty"`
+	UserTitleEnabled        *bool          `json:"UserTitleEnabled,omitempty"`
+	UUID                    string         `json:"UUID"`
+	VisibleInActionsList    *bool          `json:"VisibleInActionsList,omitempty"`
+}
+
+// State A state of an action. Actions have 1 or 2 states.
+type State struct {
+	FontFamily       string      `json:"FontFamily,omitempty"`
+	FontSize         json.Number `json:"FontSize,omitempty"`
+	FontStyle        string      `json:"FontStyle,omitempty"`
+	FontUnderline    bool        `json:"FontUnderline,omitempty"`
+	Image            string      `json:"Image"`
+	MultiActionImage string      `json:"MultiActionImage,omitempty"`
+	Name             string      `json:"Name,omitempty"`
+	ShowTitle        *bool       `json:"ShowTitle,omitempty"`
+	Title            string      `json:"Title,omitempty"`
+	TitleAlignment   string      `json:"TitleAlignment,omitempty"`
+	TitleColor       string      `json:"TitleColor,omitempty"`
+}
+
+// ActionEncoder Display of an action on the dials and touch display of a Stream Deck +.
+type ActionEncoder struct {
+	Icon               string              `json:"Icon,omitempty"`
+	Background         string              `json:"background,omitempty"`
+	Layout             string              `json:"layout,omitempty"`
+	StackColor         string              `json:"StackColor,omitempty"`
+	TriggerDescription *TriggerDescription `json:"TriggerDescription,omitempty"`
+}
+
+// TriggerDescription Descriptions of the encoder interactions shown in the Stream Deck software.
+type TriggerDescription struct {
+	LongTouch string `json:"LongTouch,omitempty"`
+	Push      string `json:"Push,omitempty"`
+	Rotate    string `json:"Rotate,omitempty"`
+	Touch     string `json:"Touch,omitempty"`
+}
+
+// OS An operating system the plugin supports.
+type OS struct {
+	Platform       Platform `json:"Platform"`
+	MinimumVersion string   `json:"MinimumVersion"`
+}
+
+// Software Version of the Stream Deck software the plugin requires.
+type Software struct {
+	MinimumVersion string `json:"MinimumVersion"`
+}
+
+// Profile A profile distributed with the plugin, the .streamDeckProfile file at Name.
+type Profile struct {
+	Name                        string `json:"Name"`
+	DeviceType                  int    `json:"DeviceType"`
+	ReadOnly                    bool   `json:"ReadOnly,omitempty"`
+	DontAutoSwitchWhenInstalled bool   `json:"DontAutoSwitchWhenInstalled,omitempty"`
+	AutoInstall                 *bool  `json:"AutoInstall,omitempty"`
+}
+
+// ApplicationsToMonitor Applications the plugin receives applicationDidLaunch and applicationDidTerminate for, per platform.
+type ApplicationsToMonitor struct {
+	Mac     []string `json:"mac,omitempty"`
+	Windows []string `json:"windows,omitempty"`
+}
+
+// Parse Parse a manifest.
+func Parse(b []byte) (*Manifest, error) {
+	// manifests are edited by hand, strip the UTF-8 BOM some editors add
+	b = bytes.TrimPrefix(b, []byte("\xef\xbb\xbf"))
+	m := &Manifest{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, xerrors.Errorf("failed to parse manifest: %w", err)
+	}
+	return m, nil
+}
+
+// Load Load the manifest file at path.
+func Load(path string) (*Manifest, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read manifest: %w", err)
+	}
+	return Parse(b)
+}
+
+// Action Get the action with the UUID.
+func (m *Manifest) Action(uuid string) (Action, bool) {
+	for _, a := range m.Actions {
+		if a.UUID == uuid {
+			return a, true
+		}
+	}
+	return Action{}, false
+}
+
+// WriteFile Write the manifest to path as indented JSON.
+func (m *Manifest) WriteFile(path string) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return xerrors.Errorf("failed to marshal manifest: %w", err)
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
@@ -0,0 +1,116 @@
+package manifest_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/FlowingSPDG/streamdeck/manifest"
+)
+
+const valid = `{
+  "Actions": [
+    {
+      "Icon": "images/action",
+      "Name": "Counter",
+      "States": [{"Image": "images/key", "FontSize": "24", "TitleAlignment": "middle"}],
+      "Tooltip": "Count something!",
+      "UUID": "dev.samwho.streamdeck.counter",
+      "PropertyInspectorPath": "pi.html"
+    },
+    {
+      "Icon": "images/action",
+      "Name": "Volume",
+      "States": [{"Image": "images/key"}],
+      "Controllers": ["Encoder"],
+      "Encoder": {"layout": "$B1", "TriggerDescription": {"Rotate": "Volume"}},
+      "UUID": "dev.samwho.streamdeck.volume"
+    }
+  ],
+  "SDKVersion": 2,
+  "Author": "Sam Rose",
+  "CodePath": "counter.exe",
+  "Description": "Count something!",
+  "Icon": "images/plugin",
+  "Name": "Counter",
+  "Version": "0.1",
+  "OS": [{"Platform": "mac", "MinimumVersion": "10.11"}, {"Platform": "windows", "MinimumVersion": "10"}],
+  "Software": {"MinimumVersion": "6.4"},
+  "Profiles": [{"Name": "profiles/Counter", "DeviceType": 0}]
+}`
+
+var files = fstest.MapFS{
+	"images/action.png":                  {},
+	"images/action@2x.png":               {},
+	"images/key.svg":                     {},
+	"images/plugin.png":                  {},
+	"pi.html":                            {},
+	"profiles/Counter.streamDeckProfile": {},
+}
+
+func TestValidate(t *testing.T) {
+	m, err := manifest.Parse([]byte(valid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Actions[0].States[0].FontSize != "24" {
+		t.Errorf("FontSize = %q, want 24", m.Actions[0].States[0].FontSize)
+	}
+	if err := m.Validate(files, "dev.samwho.streamdeck.counter", "dev.samwho.streamdeck.volume"); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	m, err := manifest.Parse([]byte(valid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Author = ""
+	m.Actions[0].UUID = "Dev.Counter!"
+	m.Actions[1].States[0].Image = "images/missing"
+	m.Actions[1].Controllers = nil
+
+	err = m.Validate(files, "dev.samwho.streamdeck.counter")
+	for _, target := range []error{
+		manifest.ErrMissingField,
+		manifest.ErrInvalidUUID,
+		manifest.ErrFileNotFound,
+		manifest.ErrInvalidValue,
+		manifest.ErrUnregisteredUUID,
+	} {
+		if !errors.Is(err, target) {
+			t.Errorf("Validate() = %v, want %v", err, target)
+		}
+	}
+}
+
+func TestValidateWithoutFiles(t *testing.T) {
+	m, err := manifest.Parse([]byte(valid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Actions[0].PropertyInspectorPath = "missing.html"
+	if err := m.Validate(nil); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	m, err := manifest.Parse([]byte(valid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), manifest.FileName)
+	if err := m.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := manifest.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, ok := loaded.Action("dev.samwho.streamdeck.volume"); !ok || a.Encoder.Layout != "$B1" {
+		t.Errorf("Action() = %+v, %v", a, ok)
+	}
+}
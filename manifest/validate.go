@@ -0,0 +1,191 @@
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Validation errors
+var (
+	ErrMissingField     = errors.New("missing required field")
+	ErrInvalidUUID      = errors.New("invalid UUID")
+	ErrDuplicateUUID    = errors.New("duplicate action UUID")
+	ErrFileNotFound     = errors.New("file not found")
+	ErrInvalidValue     = errors.New("invalid value")
+	ErrUnregisteredUUID = errors.New("action is not in the manifest")
+)
+
+// uuidPattern reverse-DNS identifier with lowercase alphanumeric characters, hyphens and periods, e.g. "com.elgato.example.action".
+var uuidPattern = regexp.MustCompile(`^[a-z0-9-]+(\.[a-z0-9-]+)+$`)
+
+// imageExtensions extensions tried for image paths, which the manifest specifies without extension.
+var imageExtensions = []string{".png", "@2x.png", ".svg", ".gif", ".jpg", ".jpeg", ""}
+
+// Validate Check the manifest: required fields, UUID formats, and actions registered in code.
+// When fsys is not nil, it is the .sdPlugin directory, and the images, property inspectors and profiles the manifest refers to must exist in it.
+// registered are the action UUIDs the plugin registers handlers for, e.g. client.ActionUUIDs(). They must all be in the manifest.
+// All problems are reported, joined with errors.Join.
+func (m *Manifest) Validate(fsys fs.FS, registered ...string) error {
+	v := &validator{fsys: fsys}
+
+	v.required("Author", m.Author)
+	v.required("Description", m.Description)
+	v.required("Name", m.Name)
+	v.required("Version", m.Version)
+	v.image("Icon", m.Icon, true)
+	v.image("CategoryIcon", m.CategoryIcon, false)
+	v.file("PropertyInspectorPath", m.PropertyInspectorPath)
+	if m.CodePath == "" && (m.CodePathMac == "" || m.CodePathWin == "") {
+		v.errorf("%w: CodePath, or CodePathMac and CodePathWin", ErrMissingField)
+	}
+	if m.SDKVersion == 0 {
+		v.errorf("%w: SDKVersion", ErrMissingField)
+	} else if m.SDKVersion != SDKVersion {
+		v.errorf("%w: SDKVersion %d, want %d", ErrInvalidValue, m.SDKVersion, SDKVersion)
+	}
+	if m.UUID != "" && !uuidPattern.MatchString(m.UUID) {
+		v.errorf("%w: plugin UUID %q", ErrInvalidUUID, m.UUID)
+	}
+
+	if len(m.OS) == 0 {
+		v.errorf("%w: OS", ErrMissingField)
+	}
+	for i, os := range m.OS {
+		if os.Platform != Mac && os.Platform != Windows {
+			v.errorf("%w: OS[%d].Platform %q", ErrInvalidValue, i, os.Platform)
+		}
+		v.required(fmt.Sprintf("OS[%d].MinimumVersion", i), os.MinimumVersion)
+	}
+	v.required("Software.MinimumVersion", m.Software.MinimumVersion)
+
+	for i, p := range m.Profiles {
+		field := fmt.Sprintf("Profiles[%d].Name", i)
+		v.required(field, p.Name)
+		if p.Name != "" {
+			v.file(field, p.Name+".streamDeckProfile")
+		}
+	}
+
+	if len(m.Actions) == 0 {
+		v.errorf("%w: Actions", ErrMissingField)
+	}
+	seen := map[string]bool{}
+	for i, a := range m.Actions {
+		v.action(fmt.Sprintf("Actions[%d]", i), a)
+		if seen[a.UUID] {
+			v.errorf("%w: %q", ErrDuplicateUUID, a.UUID)
+		}
+		seen[a.UUID] = true
+	}
+
+	for _, uuid := range registered {
+		if !seen[uuid] {
+			v.errorf("%w: %q", ErrUnregisteredUUID, uuid)
+		}
+	}
+
+	return errors.Join(v.errs...)
+}
+
+type validator struct {
+	fsys fs.FS
+	errs []error
+}
+
+func (v *validator) errorf(format string, args ...any) {
+	v.errs = append(v.errs, xerrors.Errorf(format, args...))
+}
+
+func (v *validator) required(field, value string) {
+	if value == "" {
+		v.errorf("%w: %s", ErrMissingField, field)
+	}
+}
+
+func (v *validator) action(prefix string, a Action) {
+	v.required(prefix+".Name", a.Name)
+	v.required(prefix+".UUID", a.UUID)
+	if a.UUID != "" && !uuidPattern.MatchString(a.UUID) {
+		v.errorf("%w: %s.UUID %q", ErrInvalidUUID, prefix, a.UUID)
+	}
+	v.image(prefix+".Icon", a.Icon, true)
+	v.file(prefix+".PropertyInspectorPath", a.PropertyInspectorPath)
+
+	if len(a.States) == 0 || len(a.States) > 2 {
+		v.errorf("%w: %s has %d states, want 1 or 2", ErrInvalidValue, prefix, len(a.States))
+	}
+	for i, s := range a.States {
+		field := fmt.Sprintf("%s.States[%d]", prefix, i)
+		v.image(field+".Image", s.Image, true)
+		v.image(field+".MultiActionImage", s.MultiActionImage, false)
+		if s.FontSize != "" {
+			if _, err := s.FontSize.Int64(); err != nil {
+				v.errorf("%w: %s.FontSize %q", ErrInvalidValue, field, s.FontSize)
+			}
+		}
+	}
+
+	encoder := false
+	for _, c := range a.Controllers {
+		switch c {
+		case Keypad:
+		case Encoder:
+			encoder = true
+		default:
+			v.errorf("%w: %s.Controllers %q", ErrInvalidValue, prefix, c)
+		}
+	}
+	if a.Encoder != nil {
+		if !encoder {
+			v.errorf("%w: %s.Encoder is set but Controllers doesn't include %s", ErrInvalidValue, prefix, Encoder)
+		}
+		v.image(prefix+".Encoder.Icon", a.Encoder.Icon, false)
+		v.image(prefix+".Encoder.background", a.Encoder.Background, false)
+		// layouts are either built-in ("$X1", "$B1"...) or a JSON file of the plugin
+		if a.Encoder.Layout != "" && !strings.HasPrefix(a.Encoder.Layout, "$") {
+			v.file(prefix+".Encoder.layout", a.Encoder.Layout)
+		}
+	}
+}
+
+// image checks the image at p, without extension, exists.
+func (v *validator) image(field, p string, required bool) {
+	if p == "" {
+		if required {
+			v.errorf("%w: %s", ErrMissingField, field)
+		}
+		return
+	}
+	if v.fsys == nil {
+		return
+	}
+	for _, ext := range imageExtensions {
+		if exists(v.fsys, p+ext) {
+			return
+		}
+	}
+	v.errorf("%w: %s %q", ErrFileNotFound, field, p)
+}
+
+// file checks the optional file at p exists.
+func (v *validator) file(field, p string) {
+	if p == "" || v.fsys == nil {
+		return
+	}
+	if !exists(v.fsys, p) {
+		v.errorf("%w: %s %q", ErrFileNotFound, field, p)
+	}
+}
+
+func exists(fsys fs.FS, p string) bool {
+	// manifests use paths relative to the .sdPlugin directory, sometimes with Windows separators
+	p = path.Clean(strings.ReplaceAll(p, `\`, "/"))
+	info, err := fs.Stat(fsys, p)
+	return err == nil && !info.IsDir()
+}
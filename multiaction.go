@@ -0,0 +1,61 @@
+package streamdeck
+
+import (
+	"context"
+
+	sdcontext "github.com/FlowingSPDG/streamdeck/context"
+)
+
+// trackMultiAction keeps the contexts of instances in multi-actions, from the events received.
+func (client *Client) trackMultiAction(event Event) {
+	switch event.Event {
+	case WillAppear, KeyDown, KeyUp:
+		var p struct {
+			IsInMultiAction bool `json:"isInMultiAction"`
+		}
+		if err := event.UnmarshalPayload(&p); err != nil {
+			return
+		}
+		if p.IsInMultiAction {
+			client.multiActions.Store(event.Context, struct{}{})
+		} else {
+			client.multiActions.Delete(event.Context)
+		}
+	case WillDisappear:
+		client.multiActions.Delete(event.Context)
+	}
+}
+
+// InMultiAction Check if the action instance of ctx is part of a multi-action.
+// Instances in multi-actions have no key of their own: SetTitle and SetImage are ignored for them, and the state to switch to is given by userDesiredState.
+func (client *Client) InMultiAction(ctx context.Context) bool {
+	if sdcontext.IsInMultiAction(ctx) {
+		return true
+	}
+	_, ok := client.multiActions.Load(sdcontext.Context(ctx))
+	return ok
+}
+
+// NextState Get the state the instance should be in after this press, for an action with the number of states:
+// the state the user chose in the multi-action, or the state following the current one on a key.
+func (p KeyDownPayload[T]) NextState(states int) int {
+	return nextState(p.State, p.UserDesiredState, p.IsInMultiAction, states)
+}
+
+// NextState Get the state the instance should be in after this press, for an action with the number of states:
+// the state the user chose in the multi-action, or the state following the current one, as the Stream Deck software shows after keyUp.
+func (p KeyUpPayload[T]) NextState(states int) int {
+	return nextState(p.State, p.UserDesiredState, p.IsInMultiAction, states)
+}
+
+func nextState(state, desired int, inMultiAction bool, states int) int {
+	if states < 1 {
+		states = 1
+	}
+	if inMultiAction {
+		state = desired
+	} else {
+		state++
+	}
+	return (state%states + states) % states
+}
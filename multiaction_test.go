@@ -0,0 +1,72 @@
+package streamdeck_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/FlowingSPDG/streamdeck"
+	sdcontext "github.com/FlowingSPDG/streamdeck/context"
+	"github.com/FlowingSPDG/streamdeck/sdtest"
+)
+
+func TestMultiAction(t *testing.T) {
+	srv := sdtest.NewServer(t)
+	client := streamdeck.NewClient(context.Background(), srv.RegistrationParams())
+	flags := make(chan bool, 2)
+	streamdeck.OnKeyDown(client.Action("com.example.action"), func(ctx context.Context, client *streamdeck.Client, p streamdeck.KeyDownPayload[struct{}]) error {
+		flags <- sdcontext.IsInMultiAction(ctx)
+		return client.SetTitle(ctx, "pressed", streamdeck.HardwareAndSoftware)
+	})
+	srv.RunClient(client)
+
+	multi := sdtest.NewInstance("com.example.action", 0, 0)
+	multi.IsInMultiAction = true
+	srv.WillAppear(multi, nil)
+	srv.KeyDown(multi, nil)
+	if !<-flags {
+		t.Error("IsInMultiAction() = false in a multi-action")
+	}
+	srv.ExpectNone(streamdeck.SetTitle, multi.Context, 50*time.Millisecond)
+	if !client.InMultiAction(sdcontext.WithContext(context.Background(), multi.Context)) {
+		t.Error("InMultiAction() = false for a multi-action instance")
+	}
+
+	key := sdtest.NewInstance("com.example.action", 1, 0)
+	srv.WillAppear(key, nil)
+	srv.KeyDown(key, nil)
+	if <-flags {
+		t.Error("IsInMultiAction() = true on a key")
+	}
+	srv.ExpectSetTitle(key.Context, "pressed")
+
+	srv.WillDisappear(multi, nil)
+	ctx := sdcontext.WithContext(context.Background(), multi.Context)
+	deadline := time.Now().Add(srv.Timeout)
+	for client.InMultiAction(ctx) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if client.InMultiAction(ctx) {
+		t.Error("InMultiAction() = true after willDisappear")
+	}
+}
+
+func TestNextState(t *testing.T) {
+	tests := []struct {
+		name string
+		p    streamdeck.KeyUpPayload[struct{}]
+		want int
+	}{
+		{name: "advance", p: streamdeck.KeyUpPayload[struct{}]{State: 0}, want: 1},
+		{name: "wrap", p: streamdeck.KeyUpPayload[struct{}]{State: 1}, want: 0},
+		{name: "desired", p: streamdeck.KeyUpPayload[struct{}]{State: 0, UserDesiredState: 0, IsInMultiAction: true}, want: 0},
+		{name: "desired on", p: streamdeck.KeyUpPayload[struct{}]{State: 0, UserDesiredState: 1, IsInMultiAction: true}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.NextState(2); got != tt.want {
+				t.Errorf("NextState(2) = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package streamdeck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	sdcontext "github.com/FlowingSPDG/streamdeck/context"
+)
+
+// StateTransition A change of the state of a multi-state action instance.
+type StateTransition struct {
+	From, To int
+	// Desired the transition was requested by a multi-action with the state the user chose for it, rather than by a key press.
+	Desired bool
+}
+
+// TransitionHandler Decide a state transition. Returning nil confirms it; returning ErrTransitionVetoed,
+// or any other error, vetoes it and the instance is put back to its previous state.
+type TransitionHandler func(ctx context.Context, client *Client, t StateTransition) error
+
+// MultiState The state of a multi-state action, for each action instance.
+// The Stream Deck software advances the state of a key on keyUp by itself; MultiState tracks it, lets handlers veto the change,
+// applies the userDesiredState of multi-actions, and restores the state of instances on willAppear.
+type MultiState struct {
+	states int
+
+	mu          sync.Mutex
+	current     map[string]int
+	transitions []TransitionHandler
+	onChange    []func(ctx context.Context, client *Client, t StateTransition) error
+}
+
+// NewMultiState Bind a new multi-state helper to an action with the number of states declared in its manifest.
+func NewMultiState(action *Action, states int) *MultiState {
+	if states < 1 {
+		states = 1
+	}
+	m := &MultiState{
+		states:  states,
+		current: map[string]int{},
+	}
+
+	OnWillAppear(action, func(ctx context.Context, client *Client, p WillAppearPayload[json.RawMessage]) error {
+		m.mu.Lock()
+		state, ok := m.current[sdcontext.Context(ctx)]
+		if !ok || p.IsInMultiAction {
+			m.current[sdcontext.Context(ctx)] = m.bound(p.State)
+		}
+		m.mu.Unlock()
+
+		// the state was set while the instance was not visible
+		if ok && !p.IsInMultiAction && state != p.State {
+			return client.SetState(ctx, state)
+		}
+		return nil
+	})
+
+	OnKeyUp(action, func(ctx context.Context, client *Client, p KeyUpPayload[json.RawMessage]) error {
+		t := StateTransition{From: m.bound(p.State), To: p.NextState(m.states)}
+		if p.IsInMultiAction {
+			// instances in multi-actions are not advanced by the Stream Deck software
+			t = StateTransition{From: m.State(ctx), To: p.NextState(m.states), Desired: true}
+		}
+		return m.transition(ctx, client, t, !p.IsInMultiAction)
+	})
+
+	OnWillDisappear(action, func(ctx context.Context, client *Client, p WillDisappearPayload[json.RawMessage]) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.current, sdcontext.Context(ctx))
+		return nil
+	})
+
+	return m
+}
+
+// NewToggle Bind a new multi-state helper to an action with two states, off (0) and on (1).
+func NewToggle(action *Action) *MultiState {
+	return NewMultiState(action, 2)
+}
+
+// OnTransition Register a handler deciding the transitions of the instances. Handlers are called in order until one vetoes.
+// Multi-actions may request the state the instance is already in, handlers are called for it too.
+func (m *MultiState) OnTransition(handler TransitionHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.transitions = append(m.transitions, handler)
+}
+
+// OnChange Register a handler called after the state of an action instance changed.
+func (m *MultiState) OnChange(handler func(ctx context.Context, client *Client, t StateTransition) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onChange = append(m.onChange, handler)
+}
+
+// State Get the current state of the action instance of ctx.
+func (m *MultiState) State(ctx context.Context) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.current[sdcontext.Context(ctx)]
+}
+
+// On Check if the action instance of ctx is in a state other than the first one, e.g. a toggle that is on.
+func (m *MultiState) On(ctx context.Context) bool {
+	return m.State(ctx) != 0
+}
+
+// Set Set the state of the action instance of ctx, e.g. when the state changed outside of the Stream Deck.
+// Transition handlers are not called. The state is kept and restored on willAppear when the instance is not visible.
+func (m *MultiState) Set(ctx context.Context, client *Client, state int) error {
+	state = m.bound(state)
+	m.mu.Lock()
+	from := m.current[sdcontext.Context(ctx)]
+	m.current[sdcontext.Context(ctx)] = state
+	m.mu.Unlock()
+
+	if err := client.SetState(ctx, state); err != nil {
+		return err
+	}
+	if from == state {
+		return nil
+	}
+	return m.changed(ctx, client, StateTransition{From: from, To: state})
+}
+
+// Toggle Advance the action instance of ctx to its next state.
+func (m *MultiState) Toggle(ctx context.Context, client *Client) error {
+	return m.Set(ctx, client, m.State(ctx)+1)
+}
+
+// transition decides t, advanced tells if the Stream Deck software already showed its new state.
+func (m *MultiState) transition(ctx context.Context, client *Client, t StateTransition, advanced bool) error {
+	m.mu.Lock()
+	handlers := m.transitions
+	m.mu.Unlock()
+
+	for _, h := range handlers {
+		if err := h(ctx, client, t); err != nil {
+			m.mu.Lock()
+			m.current[sdcontext.Context(ctx)] = t.From
+			m.mu.Unlock()
+			if advanced {
+				if err := client.SetState(ctx, t.From); err != nil {
+					return err
+				}
+			}
+			if errors.Is(err, ErrTransitionVetoed) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	m.current[sdcontext.Context(ctx)] = t.To
+	m.mu.Unlock()
+	if !advanced {
+		if err := client.SetState(ctx, t.To); err != nil {
+			return err
+		}
+	}
+	if t.From == t.To {
+		return nil
+	}
+	return m.changed(ctx, client, t)
+}
+
+func (m *MultiState) changed(ctx context.Context, client *Client, t StateTransition) error {
+	m.mu.Lock()
+	handlers := m.onChange
+	m.mu.Unlock()
+
+	for _, h := range handlers {
+		if err := h(ctx, client, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bound wraps state around the number of states.
+func (m *MultiState) bound(state int) int {
+	return (state%m.states + m.states) % m.states
+}
@@ -0,0 +1,86 @@
+package streamdeck_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/FlowingSPDG/streamdeck"
+	sdcontext "github.com/FlowingSPDG/streamdeck/context"
+	"github.com/FlowingSPDG/streamdeck/sdtest"
+)
+
+func TestMultiState(t *testing.T) {
+	sim := sdtest.NewSimulator(t)
+	client := streamdeck.NewClient(context.Background(), sim.RegistrationParams())
+	toggle := streamdeck.NewToggle(client.Action("com.example.toggle"))
+	var veto atomic.Bool
+	toggle.OnTransition(func(ctx context.Context, client *streamdeck.Client, t streamdeck.StateTransition) error {
+		if veto.Load() {
+			return streamdeck.ErrTransitionVetoed
+		}
+		return nil
+	})
+	changes := make(chan streamdeck.StateTransition, 10)
+	toggle.OnChange(func(ctx context.Context, client *streamdeck.Client, t streamdeck.StateTransition) error {
+		changes <- t
+		return nil
+	})
+	// registered after the toggle, so its willAppear handler ran once the title is set
+	client.Action("com.example.toggle").RegisterHandler(streamdeck.WillAppear, func(ctx context.Context, client *streamdeck.Client, event streamdeck.Event) error {
+		return client.SetTitle(ctx, "appeared", streamdeck.HardwareAndSoftware)
+	})
+	sim.RunClient(client)
+
+	device := sim.Deck().Devices()[0].ID
+	inst := sim.Place("com.example.toggle", device, 0, 0, nil)
+	sim.SetStates(inst, 2)
+	ctx := sdcontext.WithContext(context.Background(), inst.Context)
+
+	sim.Press(inst)
+	if got := <-changes; got != (streamdeck.StateTransition{From: 0, To: 1}) {
+		t.Errorf("transition = %+v, want 0 to 1", got)
+	}
+	if !toggle.On(ctx) || sim.Key(inst).State != 1 {
+		t.Errorf("State() = %d, key state %d, want 1", toggle.State(ctx), sim.Key(inst).State)
+	}
+
+	veto.Store(true)
+	sim.Press(inst)
+	sim.ExpectSetState(inst.Context, 1)
+	if toggle.State(ctx) != 1 || sim.Key(inst).State != 1 {
+		t.Errorf("State() = %d, key state %d after veto, want 1", toggle.State(ctx), sim.Key(inst).State)
+	}
+	veto.Store(false)
+
+	// a multi-action asking for the off state
+	multi := sdtest.NewInstance("com.example.toggle", 0, 0)
+	multi.IsInMultiAction = true
+	multi.State = 1
+	sim.WillAppear(multi, nil)
+	sim.KeyUp(multi, nil, 0)
+	sim.ExpectSetState(multi.Context, 0)
+	if got := <-changes; got != (streamdeck.StateTransition{From: 1, To: 0, Desired: true}) {
+		t.Errorf("transition = %+v, want desired 1 to 0", got)
+	}
+
+	// the state set while the key is on another page is restored when it appears
+	if err := toggle.Set(ctx, client, 0); err != nil {
+		t.Fatal(err)
+	}
+	sim.ExpectSetState(inst.Context, 0)
+	<-changes
+	stale := sim.Key(inst).Instance
+	stale.State = 1
+	sim.WillAppear(stale, nil)
+	sim.ExpectSetState(inst.Context, 0)
+	sim.ExpectSetTitle(inst.Context, "appeared")
+
+	// the state of removed instances is forgotten, the next willAppear starts from its payload
+	sim.WillDisappear(stale, nil)
+	sim.WillAppear(stale, nil)
+	sim.ExpectSetTitle(inst.Context, "appeared")
+	if got := toggle.State(ctx); got != 1 {
+		t.Errorf("State() = %d after willDisappear, want 1 from willAppear", got)
+	}
+}
@@ -49,7 +49,8 @@ type SetStatePayload struct {
 // SwitchProfilePayload The name of the profile to switch to. The name should be identical to the name provided in the manifest.json file.
 type SwitchProfilePayload struct {
 	Profile string `json:"profile,omitempty"`
-	Page    int    `json:"page,omitempty"`
+	// Page zero-based index of the page to show, the page the profile was left on when nil.
+	Page *int `json:"page,omitempty"`
 }
 
 // DidReceiveSettingsPayload This json object contains persistently stored data.
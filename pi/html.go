@@ -0,0 +1,233 @@
+package pi
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"os"
+	"reflect"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// ErrInvalidTag the sdpi tag of a settings field is malformed or does not match the type of the field.
+var ErrInvalidTag = errors.New("invalid sdpi tag")
+
+// Input types of the sdpi tag.
+const (
+	InputText     = "text"
+	InputTextarea = "textarea"
+	InputNumber   = "number"
+	InputRange    = "range"
+	InputCheckbox = "checkbox"
+	InputSelect   = "select"
+	InputColor    = "color"
+)
+
+// Kinds of the values of fields, as saved in the settings.
+const (
+	KindString = "string"
+	KindInt    = "int"
+	KindFloat  = "float"
+	KindBool   = "bool"
+)
+
+// Field input of a settings field in a generated property inspector.
+type Field struct {
+	// Key JSON name of the field in the settings.
+	Key   string
+	Label string
+	// Type one of the Input types.
+	Type string
+	// Kind one of the Kind values, the value of the input is converted to it before it is saved.
+	Kind        string
+	Min         string
+	Max         string
+	Step        string
+	Placeholder string
+	Options     []Option
+}
+
+// Option option of a select field.
+type Option struct {
+	Value string
+	Label string
+}
+
+//go:embed property_inspector.html.tmpl
+var htmlFS embed.FS
+
+// generatedHeader comment of generated pages, html/template leaves out the comments of the template itself.
+const generatedHeader = "<!-- Code generated by github.com/FlowingSPDG/streamdeck/pi from the sdpi tags of the settings. DO NOT EDIT. -->"
+
+var htmlTemplate = template.Must(template.ParseFS(htmlFS, "property_inspector.html.tmpl"))
+
+// Fields Get the inputs of the fields of the settings struct with an sdpi tag. The tag is a comma separated list of key=value:
+//
+//	Text     string  `json:"text" sdpi:"label=Text,placeholder=Shown on the key"`
+//	ShowText bool    `json:"showText" sdpi:"label=Show text,type=checkbox"`
+//	Mode     string  `json:"mode" sdpi:"label=Mode,type=select,options=cpu:CPU|mem:Memory"`
+//	Opacity  float64 `json:"opacity" sdpi:"label=Opacity,type=range,min=0,max=1,step=0.1"`
+//	Color    string  `json:"color" sdpi:"type=color"`
+//
+// The type defaults to text for strings, number for numbers and checkbox for booleans, the label to the field name.
+// Options of selects are separated by |, with an optional :label.
+func Fields(settings any) ([]Field, error) {
+	t := reflect.TypeOf(settings)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, xerrors.Errorf("%w: settings must be a struct, got %T", ErrInvalidTag, settings)
+	}
+
+	var fields []Field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("sdpi")
+		if !ok || tag == "-" || !sf.IsExported() {
+			continue
+		}
+		f, err := parseField(sf, tag)
+		if err != nil {
+			return nil, xerrors.Errorf("%w: field %s: %v", ErrInvalidTag, sf.Name, err)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func parseField(sf reflect.StructField, tag string) (Field, error) {
+	f := Field{Key: sf.Name, Label: sf.Name}
+	if name, _, _ := strings.Cut(sf.Tag.Get("json"), ","); name == "-" {
+		return f, xerrors.New("field is not saved in the settings")
+	} else if name != "" {
+		f.Key = name
+	}
+
+	switch sf.Type.Kind() {
+	case reflect.String:
+		f.Kind, f.Type = KindString, InputText
+	case reflect.Bool:
+		f.Kind, f.Type = KindBool, InputCheckbox
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f.Kind, f.Type = KindInt, InputNumber
+	case reflect.Float32, reflect.Float64:
+		f.Kind, f.Type = KindFloat, InputNumber
+	default:
+		return f, xerrors.Errorf("unsupported type %s", sf.Type)
+	}
+
+	if tag != "" {
+		for _, kv := range strings.Split(tag, ",") {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				return f, xerrors.Errorf("%q is not key=value", kv)
+			}
+			switch strings.TrimSpace(k) {
+			case "label":
+				f.Label = v
+			case "type":
+				f.Type = v
+			case "min":
+				f.Min = v
+			case "max":
+				f.Max = v
+			case "step":
+				f.Step = v
+			case "placeholder":
+				f.Placeholder = v
+			case "options":
+				for _, o := range strings.Split(v, "|") {
+					value, label, ok := strings.Cut(o, ":")
+					if !ok {
+						label = value
+					}
+					f.Options = append(f.Options, Option{Value: value, Label: label})
+				}
+			default:
+				return f, xerrors.Errorf("unknown key %q", k)
+			}
+		}
+	}
+
+	valid := map[string][]string{
+		KindString: {InputText, InputTextarea, InputSelect, InputColor},
+		KindInt:    {InputNumber, InputRange, InputSelect},
+		KindFloat:  {InputNumber, InputRange, InputSelect},
+		KindBool:   {InputCheckbox},
+	}
+	ok := false
+	for _, typ := range valid[f.Kind] {
+		ok = ok || typ == f.Type
+	}
+	if !ok {
+		return f, xerrors.Errorf("type %q is not valid for %s", f.Type, sf.Type)
+	}
+	if f.Type == InputSelect && len(f.Options) == 0 {
+		return f, xerrors.New("select without options")
+	}
+	if f.Type != InputSelect && len(f.Options) > 0 {
+		return f, xerrors.Errorf("options are only valid for selects, not %q", f.Type)
+	}
+	if f.Type == InputRange && (f.Min == "" || f.Max == "") {
+		return f, xerrors.New("range without min and max")
+	}
+	if f.Step == "" && (f.Type == InputNumber || f.Type == InputRange) {
+		switch f.Kind {
+		case KindInt:
+			f.Step = "1"
+		case KindFloat:
+			// the default step of browsers is 1, which leaves a range of 0 to 1 with two values
+			f.Step = "any"
+		}
+	}
+	return f, nil
+}
+
+// GenerateHTML Generate a property inspector page editing the fields of the settings struct described by Fields.
+// It loads the settings of the action instance, and saves them with setSettings on every change; fields without sdpi tag are kept as is.
+// The page links sdpi.css, expected next to it.
+func GenerateHTML(title string, settings any) ([]byte, error) {
+	fields, err := Fields(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := map[string]string{}
+	for _, f := range fields {
+		kinds[f.Key] = f.Kind
+	}
+	kindsJSON, err := json.Marshal(kinds)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	err = htmlTemplate.Execute(&b, struct {
+		Header template.HTML
+		Title  string
+		Fields []Field
+		Kinds  template.JS
+	}{generatedHeader, title, fields, template.JS(kindsJSON)})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to generate property inspector: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// WriteHTML Write the property inspector page of GenerateHTML to path, e.g. from go generate.
+func WriteHTML(path, title string, settings any) error {
+	b, err := GenerateHTML(title, settings)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return xerrors.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package pi
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type htmlSettings struct {
+	Text     string  `json:"text" sdpi:"label=Text,placeholder=Shown on the key"`
+	ShowText bool    `json:"showText" sdpi:"label=Show text"`
+	Mode     string  `json:"mode" sdpi:"label=Mode,type=select,options=cpu:CPU|mem:Memory"`
+	Opacity  float64 `json:"opacity" sdpi:"label=Opacity,type=range,min=0,max=1,step=0.1"`
+	Gain     float64 `json:"gain" sdpi:"label=Gain,type=range,min=0,max=1"`
+	Color    string  `json:"color,omitempty" sdpi:"type=color"`
+	Count    int     `sdpi:"label=<Count>"`
+	Internal int     `json:"internal"`
+}
+
+func TestFields(t *testing.T) {
+	fields, err := Fields(&htmlSettings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Field{
+		{Key: "text", Label: "Text", Type: InputText, Kind: KindString, Placeholder: "Shown on the key"},
+		{Key: "showText", Label: "Show text", Type: InputCheckbox, Kind: KindBool},
+		{Key: "mode", Label: "Mode", Type: InputSelect, Kind: KindString, Options: []Option{{"cpu", "CPU"}, {"mem", "Memory"}}},
+		{Key: "opacity", Label: "Opacity", Type: InputRange, Kind: KindFloat, Min: "0", Max: "1", Step: "0.1"},
+		{Key: "gain", Label: "Gain", Type: InputRange, Kind: KindFloat, Min: "0", Max: "1", Step: "any"},
+		{Key: "color", Label: "Color", Type: InputColor, Kind: KindString},
+		{Key: "Count", Label: "<Count>", Type: InputNumber, Kind: KindInt, Step: "1"},
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("Fields() = %+v\nwant %+v", fields, want)
+	}
+
+	invalid := []any{
+		"not a struct",
+		struct {
+			On bool `sdpi:"type=text"`
+		}{},
+		struct {
+			Mode string `sdpi:"type=select"`
+		}{},
+		struct {
+			Level int `sdpi:"type=range,min=0"`
+		}{},
+		struct {
+			Text string `sdpi:"label"`
+		}{},
+		struct {
+			Text string `sdpi:"size=3"`
+		}{},
+		struct {
+			Items []string `sdpi:""`
+		}{},
+		struct {
+			Text string `json:"-" sdpi:""`
+		}{},
+	}
+	for _, v := range invalid {
+		if _, err := Fields(v); !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("Fields(%#v) error = %v, want ErrInvalidTag", v, err)
+		}
+	}
+}
+
+func TestGenerateHTML(t *testing.T) {
+	b, err := GenerateHTML("Counter & co", htmlSettings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(b)
+	if !strings.HasPrefix(html, "<!-- Code generated") || !strings.Contains(html, "DO NOT EDIT") {
+		t.Error("generated page does not start with the generated header")
+	}
+	for _, s := range []string{
+		"<title>Counter &amp; co</title>",
+		`<input id="text" class="sdpi-item-value" type="text" placeholder="Shown on the key" data-setting="text">`,
+		`<input id="showText" type="checkbox" data-setting="showText">`,
+		`<option value="mem">Memory</option>`,
+		`<input id="opacity" type="range" min="0" max="1" step="0.1" data-setting="opacity">`,
+		`<input id="gain" type="range" min="0" max="1" step="any" data-setting="gain">`,
+		`<input id="color" class="sdpi-item-value" type="color" data-setting="color">`,
+		`&lt;Count&gt;`,
+		`"Count":"int"`,
+		`"showText":"bool"`,
+	} {
+		if !strings.Contains(html, s) {
+			t.Errorf("generated page does not contain %s", s)
+		}
+	}
+	if strings.Contains(html, "internal") {
+		t.Error("generated page contains a field without sdpi tag")
+	}
+}
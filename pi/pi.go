@@ -0,0 +1,161 @@
+// Package pi provides typed request/response messaging between a plugin and its property inspectors,
+// on top of sendToPlugin and sendToPropertyInspector, and property inspector pages generated from settings structs.
+//
+// Property inspectors call methods registered with Handle using the JavaScript client of FS (ClientFile):
+//
+//	const rpc = new StreamDeckRPC(websocket, actionInfo.action, inUUID);
+//	const count = await rpc.call("reset", { value: 0 });
+package pi
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/FlowingSPDG/streamdeck"
+	"golang.org/x/xerrors"
+)
+
+// ClientFile name of the JavaScript client in FS.
+const ClientFile = "streamdeck-rpc.js"
+
+// FS embedded JavaScript client, to be shipped in the .sdPlugin directory next to the property inspector HTML.
+//
+//go:embed streamdeck-rpc.js
+var FS embed.FS
+
+// Error codes, as in JSON-RPC 2.0.
+const (
+	// CodeInvalidParams params of the request can not be unmarshaled.
+	CodeInvalidParams = -32602
+	// CodeMethodNotFound no handler is registered for the method.
+	CodeMethodNotFound = -32601
+	// CodeInternal the handler failed.
+	CodeInternal = -32603
+)
+
+// ErrMethodNotFound no handler is registered for the method.
+var ErrMethodNotFound = errors.New("method not found")
+
+// Request message of a property inspector calling a method. Requests without ID are notifications and get no response.
+type Request struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Response message replying to a request, with either Result or Error.
+type Response struct {
+	ID     json.RawMessage `json:"id"`
+	Result any             `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+}
+
+// Notification message sent to property inspectors without a request, see RPC.Notify.
+type Notification struct {
+	Method string `json:"method"`
+	Params any    `json:"params,omitempty"`
+}
+
+// Error error replied to a request. Handlers return an *Error to choose the code, other errors are replied with CodeInternal.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// HandlerFunc handler of a method, called with the context of the action instance whose property inspector sent the request.
+type HandlerFunc[Req, Resp any] func(ctx context.Context, req Req) (Resp, error)
+
+type handler func(ctx context.Context, params json.RawMessage) (any, error)
+
+// RPC methods of an action, called by its property inspectors.
+type RPC struct {
+	mu      sync.RWMutex
+	methods map[string]handler
+}
+
+// New Handle the requests sent by the property inspectors of the action.
+// sendToPlugin messages without a method are left to the other handlers of the action.
+func New(action *streamdeck.Action) *RPC {
+	rpc := &RPC{methods: map[string]handler{}}
+	action.RegisterHandler(streamdeck.SendToPlugin, rpc.handle)
+	return rpc
+}
+
+// Handle Register the handler of a method, replacing any previous one.
+func Handle[Req, Resp any](rpc *RPC, method string, fn HandlerFunc[Req, Resp]) {
+	rpc.mu.Lock()
+	defer rpc.mu.Unlock()
+	rpc.methods[method] = func(ctx context.Context, params json.RawMessage) (any, error) {
+		var req Req
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &req); err != nil {
+				return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+			}
+		}
+		return fn(ctx, req)
+	}
+}
+
+// Notify Send a notification to the property inspector of the action instance of ctx.
+func (rpc *RPC) Notify(ctx context.Context, client *streamdeck.Client, method string, params any) error {
+	return client.SendToPropertyInspector(ctx, Notification{Method: method, Params: params})
+}
+
+func (rpc *RPC) handle(ctx context.Context, client *streamdeck.Client, event streamdeck.Event) error {
+	var req Request
+	if err := event.UnmarshalPayload(&req); err != nil || req.Method == "" {
+		return nil
+	}
+
+	rpc.mu.RLock()
+	h, ok := rpc.methods[req.Method]
+	rpc.mu.RUnlock()
+
+	var result any
+	err := xerrors.Errorf("%w: %s", ErrMethodNotFound, req.Method)
+	if ok {
+		result, err = h(ctx, req.Params)
+	}
+	if req.ID == nil {
+		if err != nil {
+			return xerrors.Errorf("notification %s failed: %w", req.Method, err)
+		}
+		return nil
+	}
+
+	resp := Response{ID: req.ID, Result: result}
+	if err != nil {
+		var rpcErr *Error
+		switch {
+		case errors.As(err, &rpcErr):
+			resp.Error = rpcErr
+		case errors.Is(err, ErrMethodNotFound):
+			resp.Error = &Error{Code: CodeMethodNotFound, Message: err.Error()}
+		default:
+			resp.Error = &Error{Code: CodeInternal, Message: err.Error()}
+		}
+		resp.Result = nil
+	}
+	return client.SendToPropertyInspector(ctx, resp)
+}
+
+// WriteClient Write the JavaScript client into dir, e.g. the plugin source directory from go generate.
+func WriteClient(dir string) error {
+	b, err := FS.ReadFile(ClientFile)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, ClientFile), b, 0o644); err != nil {
+		return xerrors.Errorf("failed to write %s: %w", ClientFile, err)
+	}
+	return nil
+}
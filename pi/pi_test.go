@@ -0,0 +1,106 @@
+package pi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/FlowingSPDG/streamdeck"
+	sdcontext "github.com/FlowingSPDG/streamdeck/context"
+	"github.com/FlowingSPDG/streamdeck/sdtest"
+)
+
+const testAction = "com.example.pi.action"
+
+type resetRequest struct {
+	Value int `json:"value"`
+}
+
+type resetResponse struct {
+	Context string `json:"context"`
+	Value   int    `json:"value"`
+}
+
+func TestRPC(t *testing.T) {
+	srv := sdtest.NewServer(t)
+	client := streamdeck.NewClient(context.Background(), srv.RegistrationParams())
+	action := client.Action(testAction)
+	rpc := New(action)
+	Handle(rpc, "reset", func(ctx context.Context, req resetRequest) (resetResponse, error) {
+		return resetResponse{Context: sdcontext.Context(ctx), Value: req.Value}, nil
+	})
+	Handle(rpc, "fail", func(ctx context.Context, req struct{}) (any, error) {
+		return nil, errors.New("failed")
+	})
+	Handle(rpc, "forbidden", func(ctx context.Context, req struct{}) (any, error) {
+		return nil, &Error{Code: 403, Message: "forbidden"}
+	})
+	streamdeck.OnSendToPlugin(action, func(ctx context.Context, client *streamdeck.Client, payload map[string]string) error {
+		if payload["action"] == "" {
+			return nil
+		}
+		return client.SendToPropertyInspector(ctx, map[string]string{"action": payload["action"] + "Complete"})
+	})
+	srv.RunClient(client)
+
+	inst := sdtest.NewInstance(testAction, 0, 0)
+	pi := srv.OpenPropertyInspector(inst, nil)
+
+	var resp struct {
+		ID     int            `json:"id"`
+		Result *resetResponse `json:"result"`
+		Error  *Error         `json:"error"`
+	}
+	pi.SendToPlugin(Request{ID: json.RawMessage("1"), Method: "reset", Params: json.RawMessage(`{"value":3}`)})
+	pi.Expect(&resp)
+	if resp.ID != 1 || resp.Error != nil || *resp.Result != (resetResponse{Context: inst.Context, Value: 3}) {
+		t.Errorf("reset response = %+v", resp)
+	}
+
+	tests := []struct {
+		method string
+		params string
+		code   int
+	}{
+		{method: "fail", code: CodeInternal},
+		{method: "forbidden", code: 403},
+		{method: "unknown", code: CodeMethodNotFound},
+		{method: "reset", params: `{"value":"3"}`, code: CodeInvalidParams},
+	}
+	for i, tt := range tests {
+		resp.Result, resp.Error = nil, nil
+		req := Request{ID: json.RawMessage(strconv.Itoa(2 + i)), Method: tt.method}
+		if tt.params != "" {
+			req.Params = json.RawMessage(tt.params)
+		}
+		pi.SendToPlugin(req)
+		pi.Expect(&resp)
+		if resp.ID != 2+i || resp.Result != nil || resp.Error == nil || resp.Error.Code != tt.code {
+			t.Errorf("%s response = %+v, want error %d", tt.method, resp, tt.code)
+		}
+	}
+
+	// notifications get no response, and messages without a method are left to the other handlers
+	pi.SendToPlugin(Request{Method: "reset"})
+	pi.SendToPlugin(map[string]string{"action": "legacy"})
+	var legacy map[string]string
+	pi.Expect(&legacy)
+	if legacy["action"] != "legacyComplete" {
+		t.Errorf("legacy response = %v", legacy)
+	}
+
+	if err := rpc.Notify(sdcontext.WithContext(context.Background(), inst.Context), client, "progress", 50); err != nil {
+		t.Fatal(err)
+	}
+	var n struct {
+		ID     *int   `json:"id"`
+		Method string `json:"method"`
+		Params int    `json:"params"`
+	}
+	pi.Expect(&n)
+	if n.ID != nil || n.Method != "progress" || n.Params != 50 {
+		t.Errorf("notification = %+v", n)
+	}
+}
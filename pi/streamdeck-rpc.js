@@ -0,0 +1,107 @@
+// Client of the github.com/FlowingSPDG/streamdeck/pi package, calling the methods the plugin registered with pi.Handle.
+//
+//   const rpc = new StreamDeckRPC(websocket, actionInfo.action, inUUID);
+//   rpc.on("progress", (params) => { ... });
+//   const result = await rpc.call("reset", { value: 0 });
+//
+// websocket is the connection of the property inspector, registered with its inRegisterEvent.
+(function (global) {
+  "use strict";
+
+  class StreamDeckRPCError extends Error {
+    constructor(error) {
+      super(error.message);
+      this.name = "StreamDeckRPCError";
+      this.code = error.code;
+    }
+  }
+
+  class StreamDeckRPC {
+    constructor(websocket, action, context, options) {
+      this.websocket = websocket;
+      this.action = action;
+      this.context = context;
+      this.timeout = (options && options.timeout) || 10000;
+      this.nextID = 1;
+      this.pending = new Map();
+      this.listeners = new Map();
+      this._onMessage = (e) => this._receive(e);
+      websocket.addEventListener("message", this._onMessage);
+    }
+
+    // call calls the method and resolves with its result, or rejects with a StreamDeckRPCError.
+    call(method, params) {
+      const id = this.nextID++;
+      return new Promise((resolve, reject) => {
+        const timer = setTimeout(() => {
+          this.pending.delete(id);
+          reject(new StreamDeckRPCError({ code: 0, message: method + " timed out" }));
+        }, this.timeout);
+        this.pending.set(id, { resolve, reject, timer });
+        this._send({ id: id, method: method, params: params });
+      });
+    }
+
+    // notify calls the method without waiting for a response.
+    notify(method, params) {
+      this._send({ method: method, params: params });
+    }
+
+    // on registers a listener of the notifications of the method sent by the plugin with RPC.Notify.
+    on(method, listener) {
+      if (!this.listeners.has(method)) {
+        this.listeners.set(method, []);
+      }
+      this.listeners.get(method).push(listener);
+    }
+
+    // close stops listening to the websocket and rejects the pending calls.
+    close() {
+      this.websocket.removeEventListener("message", this._onMessage);
+      this.pending.forEach((p) => {
+        clearTimeout(p.timer);
+        p.reject(new StreamDeckRPCError({ code: 0, message: "closed" }));
+      });
+      this.pending.clear();
+    }
+
+    _send(payload) {
+      this.websocket.send(JSON.stringify({
+        event: "sendToPlugin",
+        action: this.action,
+        context: this.context,
+        payload: payload,
+      }));
+    }
+
+    _receive(e) {
+      let message;
+      try {
+        message = JSON.parse(e.data);
+      } catch (err) {
+        return;
+      }
+      if (message.event !== "sendToPropertyInspector" || !message.payload) {
+        return;
+      }
+      const payload = message.payload;
+      if (payload.id !== undefined && this.pending.has(payload.id)) {
+        const p = this.pending.get(payload.id);
+        this.pending.delete(payload.id);
+        clearTimeout(p.timer);
+        if (payload.error) {
+          p.reject(new StreamDeckRPCError(payload.error));
+        } else {
+          p.resolve(payload.result);
+        }
+        return;
+      }
+      if (payload.id === undefined && payload.method && this.listeners.has(payload.method)) {
+        this.listeners.get(payload.method).forEach((listener) => listener(payload.params));
+      }
+    }
+  }
+
+  global.StreamDeckRPC = StreamDeckRPC;
+  global.StreamDeckRPCError = StreamDeckRPCError;
+})(typeof window !== "undefined" ? window : this);
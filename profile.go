@@ -0,0 +1,133 @@
+package streamdeck
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	sdcontext "github.com/FlowingSPDG/streamdeck/context"
+	"github.com/FlowingSPDG/streamdeck/manifest"
+	"golang.org/x/xerrors"
+)
+
+// ProfileSwitcher Switches devices to the profiles distributed with the plugin, and back.
+// Profiles are checked against the manifest and the type of the device before switching, and the profiles shown are remembered per device.
+type ProfileSwitcher struct {
+	client   *Client
+	profiles map[string]manifest.Profile
+
+	mu      sync.Mutex
+	devices map[string]int
+	stacks  map[string][]profileEntry
+}
+
+type profileEntry struct {
+	profile string
+	page    []int
+}
+
+// NewProfileSwitcher Get a profile switcher for the profiles of the manifest, e.g. manifest.Load(...).Profiles.
+func NewProfileSwitcher(client *Client, profiles []manifest.Profile) *ProfileSwitcher {
+	s := &ProfileSwitcher{
+		client:   client,
+		profiles: map[string]manifest.Profile{},
+		devices:  map[string]int{},
+		stacks:   map[string][]profileEntry{},
+	}
+	for _, p := range profiles {
+		s.profiles[p.Name] = p
+	}
+	for _, d := range client.params.Info.Devices {
+		s.devices[d.ID] = d.Type
+	}
+
+	client.RegisterNoActionHandler(DeviceDidConnect, func(ctx context.Context, client *Client, event Event) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.devices[event.Device] = int(event.DeviceInfo.Type)
+		return nil
+	})
+	client.RegisterNoActionHandler(DeviceDidDisconnect, func(ctx context.Context, client *Client, event Event) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		// the device shows its own profiles again when it reconnects
+		delete(s.stacks, event.Device)
+		return nil
+	})
+
+	return s
+}
+
+// Profile Get the manifest entry of the profile.
+func (s *ProfileSwitcher) Profile(name string) (manifest.Profile, bool) {
+	p, ok := s.profiles[name]
+	return p, ok
+}
+
+// Check Check that the profile is in the manifest and made for the type of the device of ctx.
+// Devices neither in the registration info nor connected since are rejected with ErrUnknownDevice.
+func (s *ProfileSwitcher) Check(ctx context.Context, name string) error {
+	p, ok := s.profiles[name]
+	if !ok {
+		return xerrors.Errorf("%w: %q", ErrUnknownProfile, name)
+	}
+	s.mu.Lock()
+	deviceType, ok := s.devices[sdcontext.Device(ctx)]
+	s.mu.Unlock()
+	if !ok {
+		return xerrors.Errorf("%w: %q", ErrUnknownDevice, sdcontext.Device(ctx))
+	}
+	if deviceType != p.DeviceType {
+		return xerrors.Errorf("%w: %q is for device type %d, %s is %d", ErrProfileDeviceType, name, p.DeviceType, sdcontext.Device(ctx), deviceType)
+	}
+	return nil
+}
+
+// Switch Switch the device of ctx to the profile, on the zero-based page when given, after checking it with Check.
+func (s *ProfileSwitcher) Switch(ctx context.Context, name string, page ...int) error {
+	if err := s.Check(ctx, name); err != nil {
+		return err
+	}
+	if len(page) > 0 && page[0] < 0 {
+		return xerrors.Errorf("%w: page %d", ErrInvalidMessage, page[0])
+	}
+	if err := s.client.SwitchToProfile(ctx, name, page...); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	device := sdcontext.Device(ctx)
+	s.stacks[device] = append(s.stacks[device], profileEntry{profile: name, page: slices.Clone(page)})
+	return nil
+}
+
+// SwitchBack Switch the device of ctx back to the profile shown before the last Switch.
+// After the first profile switched to, the device goes back to the profile the user had selected.
+func (s *ProfileSwitcher) SwitchBack(ctx context.Context) error {
+	device := sdcontext.Device(ctx)
+	s.mu.Lock()
+	stack := s.stacks[device]
+	if len(stack) > 0 {
+		stack = stack[:len(stack)-1]
+	}
+	var prev profileEntry
+	if len(stack) > 0 {
+		prev = stack[len(stack)-1]
+	}
+	s.stacks[device] = stack
+	s.mu.Unlock()
+
+	return s.client.SwitchToProfile(ctx, prev.profile, prev.page...)
+}
+
+// Current Get the profile the device of ctx was last switched to, empty when it shows a profile of the user.
+func (s *ProfileSwitcher) Current(ctx context.Context) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stack := s.stacks[sdcontext.Device(ctx)]
+	if len(stack) == 0 {
+		return ""
+	}
+	return stack[len(stack)-1].profile
+}
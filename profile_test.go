@@ -0,0 +1,74 @@
+package streamdeck_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/FlowingSPDG/streamdeck"
+	sdcontext "github.com/FlowingSPDG/streamdeck/context"
+	"github.com/FlowingSPDG/streamdeck/manifest"
+	"github.com/FlowingSPDG/streamdeck/sdtest"
+)
+
+func TestProfileSwitcher(t *testing.T) {
+	srv := sdtest.NewServer(t)
+	client := streamdeck.NewClient(context.Background(), srv.RegistrationParams())
+	profiles := streamdeck.NewProfileSwitcher(client, []manifest.Profile{
+		{Name: "profiles/Main", DeviceType: int(streamdeck.StreamDeck)},
+		{Name: "profiles/Numpad", DeviceType: int(streamdeck.StreamDeck)},
+		{Name: "profiles/Plus", DeviceType: int(streamdeck.StreamDeckPlus)},
+	})
+	srv.RunClient(client)
+	ctx := sdcontext.WithDevice(context.Background(), sdtest.DeviceID)
+
+	expect := func(profile string, page *int) {
+		t.Helper()
+		event := srv.Expect(streamdeck.SwitchToProfile, srv.RegistrationParams().PluginUUID, nil)
+		var p streamdeck.SwitchProfilePayload
+		if err := event.UnmarshalPayload(&p); err != nil {
+			t.Fatal(err)
+		}
+		if event.Device != sdtest.DeviceID || p.Profile != profile || (p.Page == nil) != (page == nil) || (page != nil && *p.Page != *page) {
+			b, _ := json.Marshal(event)
+			t.Errorf("switchToProfile = %s, want %q page %v", b, profile, page)
+		}
+	}
+
+	if err := profiles.Switch(ctx, "profiles/Unknown"); !errors.Is(err, streamdeck.ErrUnknownProfile) {
+		t.Errorf("Switch(unknown) = %v, want ErrUnknownProfile", err)
+	}
+	if err := profiles.Switch(ctx, "profiles/Plus"); !errors.Is(err, streamdeck.ErrProfileDeviceType) {
+		t.Errorf("Switch(Plus) = %v, want ErrProfileDeviceType", err)
+	}
+
+	if err := profiles.Switch(sdcontext.WithDevice(context.Background(), "UNKNOWN"), "profiles/Main"); !errors.Is(err, streamdeck.ErrUnknownDevice) {
+		t.Errorf("Switch(unknown device) = %v, want ErrUnknownDevice", err)
+	}
+	if err := profiles.Check(context.Background(), "profiles/Main"); !errors.Is(err, streamdeck.ErrUnknownDevice) {
+		t.Errorf("Check(no device) = %v, want ErrUnknownDevice", err)
+	}
+
+	if err := profiles.Switch(ctx, "profiles/Main", 0); err != nil {
+		t.Fatal(err)
+	}
+	zero := 0
+	expect("profiles/Main", &zero)
+	if err := profiles.Switch(ctx, "profiles/Numpad"); err != nil {
+		t.Fatal(err)
+	}
+	expect("profiles/Numpad", nil)
+
+	if err := profiles.SwitchBack(ctx); err != nil {
+		t.Fatal(err)
+	}
+	expect("profiles/Main", &zero)
+	if got := profiles.Current(ctx); got != "profiles/Main" {
+		t.Errorf("Current() = %q, want profiles/Main", got)
+	}
+	if err := profiles.SwitchBack(ctx); err != nil {
+		t.Fatal(err)
+	}
+	expect("", nil)
+}
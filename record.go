@@ -0,0 +1,120 @@
+package streamdeck
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// Direction Direction of a recorded message.
+type Direction string
+
+const (
+	// Inbound message received from the Stream Deck software
+	Inbound Direction = "in"
+	// Outbound message sent by the plugin
+	Outbound Direction = "out"
+)
+
+// RecordedMessage A WebSocket message of a recording. Recordings are JSONL files with one message per line.
+type RecordedMessage struct {
+	Time      time.Time       `json:"time"`
+	Direction Direction       `json:"direction"`
+	Message   json.RawMessage `json:"message"`
+}
+
+// Recorder Write every message of a WebSocket session to a JSONL recording. Set it on a client with Client.Record.
+type Recorder struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+}
+
+// NewRecorder Get a recorder writing to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: w, enc: json.NewEncoder(w)}
+}
+
+// NewFileRecorder Get a recorder writing to the file at path. The file is truncated.
+func NewFileRecorder(path string) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create recording: %w", err)
+	}
+	return NewRecorder(f), nil
+}
+
+// Record Write a message.
+func (r *Recorder) Record(direction Direction, message []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := RecordedMessage{
+		Time:      time.Now(),
+		Direction: direction,
+		Message:   json.RawMessage(message),
+	}
+	if err := r.enc.Encode(m); err != nil {
+		return xerrors.Errorf("%w: %v", ErrWriteFailed, err)
+	}
+	return nil
+}
+
+// Close Close the underlying writer if it is an io.Closer.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c, ok := r.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// ReadRecording Read the messages of a JSONL recording.
+func ReadRecording(r io.Reader) ([]RecordedMessage, error) {
+	var messages []RecordedMessage
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 64<<20)
+	for line := 1; s.Scan(); line++ {
+		if len(s.Bytes()) == 0 {
+			continue
+		}
+		var m RecordedMessage
+		if err := json.Unmarshal(s.Bytes(), &m); err != nil {
+			return nil, xerrors.Errorf("%w: line %d: %v", ErrInvalidMessage, line, err)
+		}
+		messages = append(messages, m)
+	}
+	if err := s.Err(); err != nil {
+		return nil, xerrors.Errorf("%w: %v", ErrReadFailed, err)
+	}
+	return messages, nil
+}
+
+// ReadRecordingFile Read the messages of the JSONL recording at path.
+func ReadRecordingFile(path string) ([]RecordedMessage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to open recording: %w", err)
+	}
+	defer f.Close()
+	return ReadRecording(f)
+}
+
+// Record Write every inbound and outbound message of the session to the recorder. It must be set before Run.
+func (client *Client) Record(r *Recorder) {
+	client.recorder = r
+}
+
+func (client *Client) record(direction Direction, message []byte) {
+	if client.recorder == nil {
+		return
+	}
+	if err := client.recorder.Record(direction, message); err != nil {
+		logger.Printf("failed to record message: %v\n", err)
+	}
+}
@@ -0,0 +1,338 @@
+package sdtest
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/FlowingSPDG/streamdeck"
+	"golang.org/x/xerrors"
+)
+
+// Controllers of a key.
+const (
+	// Keypad key of the keypad
+	Keypad = "Keypad"
+	// Encoder dial and touch display segment of a Stream Deck +
+	Encoder = "Encoder"
+)
+
+// VirtualDevice A device of the virtual deck.
+type VirtualDevice struct {
+	ID      string
+	Name    string
+	Type    streamdeck.DeviceType
+	Columns int
+	Rows    int
+	// Encoders number of dials.
+	Encoders int
+}
+
+// Key State of an action instance on the virtual deck, as set by the plugin.
+type Key struct {
+	Instance
+	Controller string
+	Settings   json.RawMessage
+	// States number of states of the action. The state advances on keyUp when it is more than 1.
+	States         int
+	Title          string
+	Image          string
+	FeedbackLayout string
+	Feedback       map[string]json.RawMessage
+	// Alert last temporary icon shown, streamdeck.ShowOk or streamdeck.ShowAlert.
+	Alert string
+}
+
+// Deck State of a virtual Stream Deck software: devices, action instances and settings.
+// It is independent of the connection to the plugin, apply messages sent by the plugin with Apply.
+type Deck struct {
+	mu      sync.Mutex
+	devices []VirtualDevice
+	keys    map[string]*Key
+	global  json.RawMessage
+}
+
+// NewDeck Get a new virtual deck with the devices of the registration info.
+func NewDeck(info streamdeck.Info) *Deck {
+	d := &Deck{keys: map[string]*Key{}}
+	for _, dev := range info.Devices {
+		vd := VirtualDevice{
+			ID:      dev.ID,
+			Name:    dev.Name,
+			Type:    streamdeck.DeviceType(dev.Type),
+			Columns: dev.Size.Columns,
+			Rows:    dev.Size.Rows,
+		}
+		if vd.Type == streamdeck.StreamDeckPlus {
+			vd.Encoders = 4
+		}
+		d.devices = append(d.devices, vd)
+	}
+	return d
+}
+
+// Devices Get the devices of the deck.
+func (d *Deck) Devices() []VirtualDevice {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]VirtualDevice{}, d.devices...)
+}
+
+// Device Get a device of the deck.
+func (d *Deck) Device(id string) (VirtualDevice, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, dev := range d.devices {
+		if dev.ID == id {
+			return dev, true
+		}
+	}
+	return VirtualDevice{}, false
+}
+
+// Place Add an instance of the action to the device at column/row of the controller. Encoders are placed at row 0.
+func (d *Deck) Place(action, device, controller string, column, row int, settings any) (Key, error) {
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return Key{}, xerrors.Errorf("%w: %v", streamdeck.ErrJSONMarshal, err)
+	}
+	if settings == nil {
+		raw = json.RawMessage("{}")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var dev *VirtualDevice
+	for i := range d.devices {
+		if d.devices[i].ID == device {
+			dev = &d.devices[i]
+		}
+	}
+	if dev == nil {
+		return Key{}, xerrors.Errorf("unknown device %q", device)
+	}
+
+	switch controller {
+	case Keypad:
+		if column < 0 || row < 0 || column >= dev.Columns || row >= dev.Rows {
+			return Key{}, xerrors.Errorf("key %d,%d is outside of %s (%dx%d)", column, row, dev.Name, dev.Columns, dev.Rows)
+		}
+	case Encoder:
+		if column < 0 || row != 0 || column >= dev.Encoders {
+			return Key{}, xerrors.Errorf("encoder %d is outside of %s (%d encoders)", column, dev.Name, dev.Encoders)
+		}
+	default:
+		return Key{}, xerrors.Errorf("unknown controller %q", controller)
+	}
+
+	for _, k := range d.keys {
+		if k.Device == device && k.Controller == controller && k.Coordinates.Column == column && k.Coordinates.Row == row {
+			return Key{}, xerrors.Errorf("%s %d,%d of %s is already used by %s", controller, column, row, dev.Name, k.Action)
+		}
+	}
+
+	k := &Key{
+		Instance: Instance{
+			Action:      action,
+			Context:     randomID(),
+			Device:      device,
+			Coordinates: streamdeck.Coordinates{Column: column, Row: row},
+		},
+		Controller: controller,
+		Settings:   raw,
+		States:     1,
+		Feedback:   map[string]json.RawMessage{},
+	}
+	d.keys[k.Context] = k
+	return k.clone(), nil
+}
+
+// Remove Remove an action instance.
+func (d *Deck) Remove(context string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.keys, context)
+}
+
+// Key Get an action instance.
+func (d *Deck) Key(context string) (Key, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	k, ok := d.keys[context]
+	if !ok {
+		return Key{}, false
+	}
+	return k.clone(), true
+}
+
+// KeyAt Get the action instance at column/row of the controller of the device.
+func (d *Deck) KeyAt(device, controller string, column, row int) (Key, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, k := range d.keys {
+		if k.Device == device && k.Controller == controller && k.Coordinates.Column == column && k.Coordinates.Row == row {
+			return k.clone(), true
+		}
+	}
+	return Key{}, false
+}
+
+// Keys Get all action instances ordered by device, controller, row and column.
+func (d *Deck) Keys() []Key {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	keys := make([]Key, 0, len(d.keys))
+	for _, k := range d.keys {
+		keys = append(keys, k.clone())
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.Device != b.Device {
+			return a.Device < b.Device
+		}
+		if a.Controller != b.Controller {
+			return a.Controller > b.Controller
+		}
+		if a.Coordinates.Row != b.Coordinates.Row {
+			return a.Coordinates.Row < b.Coordinates.Row
+		}
+		return a.Coordinates.Column < b.Coordinates.Column
+	})
+	return keys
+}
+
+// Update Change an action instance.
+func (d *Deck) Update(context string, update func(k *Key)) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	k, ok := d.keys[context]
+	if ok {
+		update(k)
+	}
+	return ok
+}
+
+// AdvanceState Advance a multi-state action instance to its next state, as the Stream Deck software does on keyUp.
+// Call it before sending keyUp, so a setState reply of the plugin is not overwritten.
+// ok is false when the instance doesn't exist, has a single state, or is part of a multi-action.
+func (d *Deck) AdvanceState(context string) (state int, ok bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	k, found := d.keys[context]
+	if !found || k.States <= 1 || k.IsInMultiAction {
+		return 0, false
+	}
+	k.State = (k.State + 1) % k.States
+	return k.State, true
+}
+
+// GlobalSettings Get the global settings.
+func (d *Deck) GlobalSettings() json.RawMessage {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.global
+}
+
+// SetGlobalSettings Change the global settings.
+func (d *Deck) SetGlobalSettings(settings json.RawMessage) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.global = settings
+}
+
+// Apply Apply a message sent by the plugin, and return the event the Stream Deck software replies with, if any.
+func (d *Deck) Apply(event streamdeck.Event) (*streamdeck.Event, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch event.Event {
+	case streamdeck.SetGlobalSettings:
+		d.global = rawPayload(event)
+		return nil, nil
+	case streamdeck.GetGlobalSettings:
+		global := d.global
+		if global == nil {
+			global = json.RawMessage("{}")
+		}
+		return &streamdeck.Event{
+			Event:   streamdeck.DidReceiveGlobalSettings,
+			Payload: streamdeck.DidReceiveGlobalSettingsPayload[json.RawMessage]{Settings: global},
+		}, nil
+	}
+
+	k, ok := d.keys[event.Context]
+	if !ok {
+		return nil, nil
+	}
+
+	switch event.Event {
+	case streamdeck.SetSettings:
+		k.Settings = rawPayload(event)
+	case streamdeck.GetSettings:
+		return &streamdeck.Event{
+			Action:  k.Action,
+			Event:   streamdeck.DidReceiveSettings,
+			Context: k.Context,
+			Device:  k.Device,
+			Payload: streamdeck.DidReceiveSettingsPayload[json.RawMessage]{
+				Settings:        k.Settings,
+				Coordinates:     k.Coordinates,
+				IsInMultiAction: k.IsInMultiAction,
+			},
+		}, nil
+	case streamdeck.SetTitle:
+		var p streamdeck.SetTitlePayload
+		if err := event.UnmarshalPayload(&p); err != nil {
+			return nil, err
+		}
+		k.Title = p.Title
+	case streamdeck.SetImage:
+		var p streamdeck.SetImagePayload
+		if err := event.UnmarshalPayload(&p); err != nil {
+			return nil, err
+		}
+		k.Image = p.Base64Image
+	case streamdeck.SetState:
+		var p streamdeck.SetStatePayload
+		if err := event.UnmarshalPayload(&p); err != nil {
+			return nil, err
+		}
+		k.State = p.State
+	case streamdeck.SetFeedback:
+		var p map[string]json.RawMessage
+		if err := event.UnmarshalPayload(&p); err != nil {
+			return nil, err
+		}
+		for key, v := range p {
+			k.Feedback[key] = v
+		}
+	case streamdeck.SetFeedbackLayout:
+		var p streamdeck.SetFeedbackLayoutPayload
+		if err := event.UnmarshalPayload(&p); err != nil {
+			return nil, err
+		}
+		k.FeedbackLayout = p.Layout
+		k.Feedback = map[string]json.RawMessage{}
+	case streamdeck.ShowOk, streamdeck.ShowAlert:
+		k.Alert = event.Event
+	}
+	return nil, nil
+}
+
+func rawPayload(event streamdeck.Event) json.RawMessage {
+	if raw, ok := event.Payload.(json.RawMessage); ok {
+		return raw
+	}
+	b, _ := json.Marshal(event.Payload)
+	return b
+}
+
+func (k *Key) clone() Key {
+	c := *k
+	c.Feedback = make(map[string]json.RawMessage, len(k.Feedback))
+	for key, v := range k.Feedback {
+		c.Feedback[key] = v
+	}
+	return c
+}
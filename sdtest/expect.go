@@ -0,0 +1,163 @@
+package sdtest
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/FlowingSPDG/streamdeck"
+)
+
+// Expect Wait for the plugin to send the event for the context that satisfies match, and return it.
+// Expectations are ordered: each one only looks at messages after the previously expected message.
+// An empty context matches any context, and a nil match accepts any payload.
+func (s *Server) Expect(eventName, context string, match func(event streamdeck.Event) bool) streamdeck.Event {
+	s.helper()
+	event, ok := s.wait(eventName, context, match, s.Timeout)
+	if !ok {
+		s.fatalf("plugin did not send %s for context %q within %v. sent: %s", eventName, context, s.Timeout, s.describe())
+	}
+	return event
+}
+
+// ExpectNone Check that the plugin doesn't send the event for the context within d.
+func (s *Server) ExpectNone(eventName, context string, d time.Duration) {
+	s.helper()
+	s.mu.Lock()
+	cursor := s.cursor
+	s.mu.Unlock()
+
+	if event, ok := s.wait(eventName, context, nil, d); ok {
+		s.fatalf("plugin unexpectedly sent %s for context %q: %v", eventName, context, event.Payload)
+	}
+
+	s.mu.Lock()
+	s.cursor = cursor
+	s.mu.Unlock()
+}
+
+// ExpectSetTitle Wait for setTitle with the title.
+func (s *Server) ExpectSetTitle(context, title string) {
+	s.helper()
+	s.Expect(streamdeck.SetTitle, context, func(event streamdeck.Event) bool {
+		var p streamdeck.SetTitlePayload
+		return event.UnmarshalPayload(&p) == nil && p.Title == title
+	})
+}
+
+// ExpectSetImage Wait for setImage, and return the image.
+func (s *Server) ExpectSetImage(context string) string {
+	s.helper()
+	var p streamdeck.SetImagePayload
+	s.Expect(streamdeck.SetImage, context, func(event streamdeck.Event) bool {
+		return event.UnmarshalPayload(&p) == nil
+	})
+	return p.Base64Image
+}
+
+// ExpectSetState Wait for setState with the state.
+func (s *Server) ExpectSetState(context string, state int) {
+	s.helper()
+	s.Expect(streamdeck.SetState, context, func(event streamdeck.Event) bool {
+		var p streamdeck.SetStatePayload
+		return event.UnmarshalPayload(&p) == nil && p.State == state
+	})
+}
+
+// ExpectSetSettings Wait for setSettings, and unmarshal the settings into v.
+func (s *Server) ExpectSetSettings(context string, v any) {
+	s.helper()
+	s.expectPayload(streamdeck.SetSettings, context, v)
+}
+
+// ExpectSetGlobalSettings Wait for setGlobalSettings, and unmarshal the settings into v.
+func (s *Server) ExpectSetGlobalSettings(v any) {
+	s.helper()
+	s.expectPayload(streamdeck.SetGlobalSettings, "", v)
+}
+
+// ExpectSetFeedback Wait for setFeedback, and unmarshal the payload into v.
+func (s *Server) ExpectSetFeedback(context string, v any) {
+	s.helper()
+	s.expectPayload(streamdeck.SetFeedback, context, v)
+}
+
+// ExpectSetFeedbackLayout Wait for setFeedbackLayout with the layout.
+func (s *Server) ExpectSetFeedbackLayout(context, layout string) {
+	s.helper()
+	s.Expect(streamdeck.SetFeedbackLayout, context, func(event streamdeck.Event) bool {
+		var p streamdeck.SetFeedbackLayoutPayload
+		return event.UnmarshalPayload(&p) == nil && p.Layout == layout
+	})
+}
+
+// ExpectSendToPropertyInspector Wait for sendToPropertyInspector, and unmarshal the payload into v.
+func (s *Server) ExpectSendToPropertyInspector(context string, v any) {
+	s.helper()
+	s.expectPayload(streamdeck.SendToPropertyInspector, context, v)
+}
+
+// ExpectShowOk Wait for showOk.
+func (s *Server) ExpectShowOk(context string) {
+	s.helper()
+	s.Expect(streamdeck.ShowOk, context, nil)
+}
+
+// ExpectShowAlert Wait for showAlert.
+func (s *Server) ExpectShowAlert(context string) {
+	s.helper()
+	s.Expect(streamdeck.ShowAlert, context, nil)
+}
+
+// ExpectOpenURL Wait for openUrl with the URL.
+func (s *Server) ExpectOpenURL(url string) {
+	s.helper()
+	s.Expect(streamdeck.OpenURL, "", func(event streamdeck.Event) bool {
+		var p streamdeck.OpenURLPayload
+		return event.UnmarshalPayload(&p) == nil && p.URL == url
+	})
+}
+
+func (s *Server) expectPayload(eventName, context string, v any) {
+	s.helper()
+	event := s.Expect(eventName, context, nil)
+	if v == nil {
+		return
+	}
+	if err := event.UnmarshalPayload(v); err != nil {
+		s.fatalf("failed to unmarshal %s payload: %v", eventName, err)
+	}
+}
+
+func (s *Server) wait(eventName, context string, match func(event streamdeck.Event) bool, d time.Duration) (streamdeck.Event, bool) {
+	deadline := time.After(d)
+	for {
+		s.mu.Lock()
+		for i := s.cursor; i < len(s.messages); i++ {
+			event := s.messages[i]
+			if event.Event != eventName || (context != "" && event.Context != context) {
+				continue
+			}
+			if match != nil && !match(event) {
+				continue
+			}
+			s.cursor = i + 1
+			s.mu.Unlock()
+			return event, true
+		}
+		notify := s.notify
+		s.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-deadline:
+			return streamdeck.Event{}, false
+		}
+	}
+}
+
+func (s *Server) describe() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, _ := json.Marshal(s.messages[s.cursor:])
+	return string(b)
+}
@@ -0,0 +1,218 @@
+package sdtest
+
+import (
+	"github.com/FlowingSPDG/streamdeck"
+)
+
+// Instance An action instance events are injected for.
+type Instance struct {
+	Action      string
+	Context     string
+	Device      string
+	Coordinates streamdeck.Coordinates
+	// State current state, for actions with multiple states.
+	State           int
+	IsInMultiAction bool
+}
+
+// NewInstance Get an instance of the action on the default Stream Deck device with a random context.
+func NewInstance(action string, column, row int) Instance {
+	return Instance{
+		Action:      action,
+		Context:     randomID(),
+		Device:      DeviceID,
+		Coordinates: streamdeck.Coordinates{Column: column, Row: row},
+	}
+}
+
+func (inst Instance) event(name string, payload any) streamdeck.Event {
+	return streamdeck.Event{
+		Action:  inst.Action,
+		Event:   name,
+		Context: inst.Context,
+		Device:  inst.Device,
+		Payload: payload,
+	}
+}
+
+// inject sends the event, failing the test on error.
+func (s *Server) inject(event streamdeck.Event) {
+	s.helper()
+	if err := s.Send(event); err != nil {
+		s.fatalf("failed to send %s: %v", event.Event, err)
+	}
+}
+
+// WillAppear Send willAppear for the instance with its settings.
+func (s *Server) WillAppear(inst Instance, settings any) {
+	s.helper()
+	s.inject(inst.event(streamdeck.WillAppear, streamdeck.WillAppearPayload[any]{
+		Settings:        settings,
+		Coordinates:     inst.Coordinates,
+		State:           inst.State,
+		IsInMultiAction: inst.IsInMultiAction,
+	}))
+}
+
+// WillDisappear Send willDisappear for the instance with its settings.
+func (s *Server) WillDisappear(inst Instance, settings any) {
+	s.helper()
+	s.inject(inst.event(streamdeck.WillDisappear, streamdeck.WillDisappearPayload[any]{
+		Settings:        settings,
+		Coordinates:     inst.Coordinates,
+		State:           inst.State,
+		IsInMultiAction: inst.IsInMultiAction,
+	}))
+}
+
+// KeyDown Send keyDown for the instance with its settings.
+func (s *Server) KeyDown(inst Instance, settings any, userDesiredState ...int) {
+	s.helper()
+	p := streamdeck.KeyDownPayload[any]{
+		Settings:        settings,
+		Coordinates:     inst.Coordinates,
+		State:           inst.State,
+		IsInMultiAction: inst.IsInMultiAction,
+	}
+	if len(userDesiredState) > 0 {
+		p.UserDesiredState = userDesiredState[0]
+	}
+	s.inject(inst.event(streamdeck.KeyDown, p))
+}
+
+// KeyUp Send keyUp for the instance with its settings.
+func (s *Server) KeyUp(inst Instance, settings any, userDesiredState ...int) {
+	s.helper()
+	p := streamdeck.KeyUpPayload[any]{
+		Settings:        settings,
+		Coordinates:     inst.Coordinates,
+		State:           inst.State,
+		IsInMultiAction: inst.IsInMultiAction,
+	}
+	if len(userDesiredState) > 0 {
+		p.UserDesiredState = userDesiredState[0]
+	}
+	s.inject(inst.event(streamdeck.KeyUp, p))
+}
+
+// DialDown Send dialDown for the instance with its settings.
+func (s *Server) DialDown(inst Instance, settings any) {
+	s.helper()
+	s.inject(inst.event(streamdeck.DialDown, streamdeck.DialDownPayload[any]{
+		Settings:    settings,
+		Coordinates: inst.Coordinates,
+		Controller:  "Encoder",
+	}))
+}
+
+// DialUp Send dialUp for the instance with its settings.
+func (s *Server) DialUp(inst Instance, settings any) {
+	s.helper()
+	s.inject(inst.event(streamdeck.DialUp, streamdeck.DialUpPayload[any]{
+		Settings:    settings,
+		Coordinates: inst.Coordinates,
+		Controller:  "Encoder",
+	}))
+}
+
+// DialRotate Send dialRotate for the instance with its settings.
+func (s *Server) DialRotate(inst Instance, settings any, ticks int, pressed bool) {
+	s.helper()
+	s.inject(inst.event(streamdeck.DialRotate, streamdeck.DialRotatePayload[any]{
+		Settings:    settings,
+		Coordinates: inst.Coordinates,
+		Ticks:       ticks,
+		Pressed:     pressed,
+	}))
+}
+
+// TouchTap Send touchTap for the instance with its settings.
+func (s *Server) TouchTap(inst Instance, settings any, pos [2]int, hold bool) {
+	s.helper()
+	s.inject(inst.event(streamdeck.TouchTap, streamdeck.TouchTapPayload[any]{
+		Settings:    settings,
+		Coordinates: inst.Coordinates,
+		TapPos:      pos,
+		Hold:        hold,
+	}))
+}
+
+// DidReceiveSettings Send didReceiveSettings for the instance.
+func (s *Server) DidReceiveSettings(inst Instance, settings any) {
+	s.helper()
+	s.inject(inst.event(streamdeck.DidReceiveSettings, streamdeck.DidReceiveSettingsPayload[any]{
+		Settings:        settings,
+		Coordinates:     inst.Coordinates,
+		IsInMultiAction: inst.IsInMultiAction,
+	}))
+}
+
+// DidReceiveGlobalSettings Send didReceiveGlobalSettings.
+func (s *Server) DidReceiveGlobalSettings(settings any) {
+	s.helper()
+	s.inject(streamdeck.Event{
+		Event:   streamdeck.DidReceiveGlobalSettings,
+		Payload: streamdeck.DidReceiveGlobalSettingsPayload[any]{Settings: settings},
+	})
+}
+
+// SendToPlugin Send sendToPlugin for the instance, as the property inspector would.
+func (s *Server) SendToPlugin(inst Instance, payload any) {
+	s.helper()
+	s.inject(inst.event(streamdeck.SendToPlugin, payload))
+}
+
+// DeviceDidConnect Send deviceDidConnect for the device of the registration info.
+func (s *Server) DeviceDidConnect(device string) {
+	s.helper()
+	event := streamdeck.Event{Event: streamdeck.DeviceDidConnect, Device: device}
+	for _, d := range s.params.Info.Devices {
+		if d.ID == device {
+			event.DeviceInfo = streamdeck.DeviceInfo{
+				DeviceName: d.Name,
+				Type:       streamdeck.DeviceType(d.Type),
+				Size:       streamdeck.DeviceSize{Columns: d.Size.Columns, Rows: d.Size.Rows},
+			}
+		}
+	}
+	s.inject(event)
+}
+
+// DeviceDidDisconnect Send deviceDidDisconnect.
+func (s *Server) DeviceDidDisconnect(device string) {
+	s.helper()
+	s.inject(streamdeck.Event{Event: streamdeck.DeviceDidDisconnect, Device: device})
+}
+
+// ApplicationDidLaunch Send applicationDidLaunch.
+func (s *Server) ApplicationDidLaunch(application string) {
+	s.helper()
+	s.inject(streamdeck.Event{
+		Event:   streamdeck.ApplicationDidLaunch,
+		Payload: streamdeck.ApplicationDidLaunchPayload{Application: application},
+	})
+}
+
+// ApplicationDidTerminate Send applicationDidTerminate.
+func (s *Server) ApplicationDidTerminate(application string) {
+	s.helper()
+	s.inject(streamdeck.Event{
+		Event:   streamdeck.ApplicationDidTerminate,
+		Payload: streamdeck.ApplicationDidTerminatePayload{Application: application},
+	})
+}
+
+// SystemDidWakeUp Send systemDidWakeUp.
+func (s *Server) SystemDidWakeUp() {
+	s.helper()
+	s.inject(streamdeck.Event{Event: streamdeck.SystemDidWakeUp})
+}
+
+// DidReceiveDeepLink Send didReceiveDeepLink.
+func (s *Server) DidReceiveDeepLink(url string) {
+	s.helper()
+	s.inject(streamdeck.Event{
+		Event:   streamdeck.DidReceiveDeepLink,
+		Payload: streamdeck.DidReceiveDeepLinkPayload{URL: url},
+	})
+}
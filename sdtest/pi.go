@@ -0,0 +1,202 @@
+package sdtest
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/FlowingSPDG/streamdeck"
+	"github.com/coder/websocket"
+	"golang.org/x/xerrors"
+)
+
+// PropertyInspector A simulated property inspector of an action instance.
+// It relays sendToPlugin messages to the plugin, and captures the sendToPropertyInspector messages the plugin sends to the instance while it is open.
+type PropertyInspector struct {
+	s      *Server
+	inst   Instance
+	remove func()
+
+	mu       sync.Mutex
+	open     bool
+	messages []json.RawMessage
+	cursor   int
+	notify   chan struct{}
+}
+
+// OpenPropertyInspector Open the property inspector of the instance: send propertyInspectorDidAppear with its settings, and start capturing messages to it.
+func (s *Server) OpenPropertyInspector(inst Instance, settings any) *PropertyInspector {
+	s.helper()
+	pi := &PropertyInspector{
+		s:      s,
+		inst:   inst,
+		open:   true,
+		notify: make(chan struct{}),
+	}
+	pi.remove = s.OnMessage(pi.capture)
+	s.inject(inst.event(streamdeck.PropertyInspectorDidAppear, streamdeck.PropertyInspectorDidAppearPayload[any]{
+		Settings:        settings,
+		Coordinates:     inst.Coordinates,
+		State:           inst.State,
+		IsInMultiAction: inst.IsInMultiAction,
+	}))
+	return pi
+}
+
+func (pi *PropertyInspector) capture(event streamdeck.Event) {
+	if event.Event != streamdeck.SendToPropertyInspector || event.Context != pi.inst.Context {
+		return
+	}
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+	if !pi.open {
+		return
+	}
+	raw, _ := event.Payload.(json.RawMessage)
+	pi.messages = append(pi.messages, raw)
+	close(pi.notify)
+	pi.notify = make(chan struct{})
+}
+
+// SendToPlugin Send a message to the plugin, as the property inspector does with sendToPlugin.
+func (pi *PropertyInspector) SendToPlugin(payload any) {
+	pi.s.helper()
+	pi.s.SendToPlugin(pi.inst, payload)
+}
+
+// Expect Wait for the plugin to send a message to the property inspector, and unmarshal it into v.
+// Like Server.Expect, expectations are ordered.
+func (pi *PropertyInspector) Expect(v any) {
+	pi.s.helper()
+	raw := pi.ExpectMessage(nil)
+	if v == nil {
+		return
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		pi.s.fatalf("failed to unmarshal %s payload: %v", streamdeck.SendToPropertyInspector, err)
+	}
+}
+
+// ExpectMessage Wait for the plugin to send a message to the property inspector that satisfies match, and return it. A nil match accepts any message.
+func (pi *PropertyInspector) ExpectMessage(match func(message json.RawMessage) bool) json.RawMessage {
+	pi.s.helper()
+	deadline := time.After(pi.s.Timeout)
+	for {
+		pi.mu.Lock()
+		for i := pi.cursor; i < len(pi.messages); i++ {
+			if match != nil && !match(pi.messages[i]) {
+				continue
+			}
+			pi.cursor = i + 1
+			pi.mu.Unlock()
+			return pi.messages[i]
+		}
+		notify := pi.notify
+		pi.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-deadline:
+			pi.s.fatalf("plugin did not send %s for context %q within %v", streamdeck.SendToPropertyInspector, pi.inst.Context, pi.s.Timeout)
+			return nil
+		}
+	}
+}
+
+// Messages Get all messages the plugin sent to the property inspector while it was open.
+func (pi *PropertyInspector) Messages() []json.RawMessage {
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+	return append([]json.RawMessage{}, pi.messages...)
+}
+
+// Close Close the property inspector: send propertyInspectorDidDisappear with the settings, and stop capturing messages.
+func (pi *PropertyInspector) Close(settings any) {
+	pi.s.helper()
+	pi.mu.Lock()
+	pi.open = false
+	pi.mu.Unlock()
+	pi.remove()
+	pi.s.inject(pi.inst.event(streamdeck.PropertyInspectorDidDisappear, streamdeck.PropertyInspectorDidDisappearPayload[any]{
+		Settings:        settings,
+		Coordinates:     pi.inst.Coordinates,
+		State:           pi.inst.State,
+		IsInMultiAction: pi.inst.IsInMultiAction,
+	}))
+}
+
+// PropertyInspectorParams Get the params a streamdeck.PropertyInspectorClient of the instance connects to the server with.
+// Connected property inspectors receive the sendToPropertyInspector messages of the plugin for their instance.
+// Their sendToPlugin messages are relayed to the plugin, and all their messages are recorded apart from those of the plugin, see InspectorMessages.
+func (s *Server) PropertyInspectorParams(inst Instance) streamdeck.PropertyInspectorParams {
+	return streamdeck.PropertyInspectorParams{
+		Port:                  s.params.Port,
+		PropertyInspectorUUID: inst.Context,
+		RegisterEvent:         streamdeck.RegisterPropertyInspector,
+		Info:                  s.params.Info,
+		ActionInfo: streamdeck.ActionInfo[json.RawMessage]{
+			Action:  inst.Action,
+			Context: inst.Context,
+			Device:  inst.Device,
+		},
+	}
+}
+
+// WaitPropertyInspector Wait for a property inspector client of the instance of context to connect and register.
+func (s *Server) WaitPropertyInspector(context string) error {
+	deadline := time.After(s.Timeout)
+	for {
+		s.mu.Lock()
+		_, ok := s.inspectors[context]
+		changed := s.inspectorsChanged
+		s.mu.Unlock()
+		if ok {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-deadline:
+			return xerrors.Errorf("property inspector of %q did not register within %v: %w", context, s.Timeout, ErrNotConnected)
+		}
+	}
+}
+
+// InspectorMessages Get all messages sent by property inspector clients, registration excluded. Payloads are json.RawMessage.
+func (s *Server) InspectorMessages() []streamdeck.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]streamdeck.Event{}, s.inspectorMessages...)
+}
+
+// fromInspector records a message of a property inspector client, and relays sendToPlugin to the plugin.
+func (s *Server) fromInspector(event streamdeck.Event) {
+	s.mu.Lock()
+	s.inspectorMessages = append(s.inspectorMessages, event)
+	s.mu.Unlock()
+	if event.Event != streamdeck.SendToPlugin {
+		return
+	}
+	if err := s.Send(event); err != nil {
+		s.logf("failed to relay %s: %v", event.Event, err)
+	}
+}
+
+// toInspector sends a sendToPropertyInspector message of the plugin to the property inspector client of its instance, if connected.
+func (s *Server) toInspector(ctx context.Context, event streamdeck.Event) {
+	s.mu.Lock()
+	conn, ok := s.inspectors[event.Context]
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+	b, err := json.Marshal(event)
+	if err != nil {
+		s.logf("failed to marshal %s: %v", event.Event, err)
+		return
+	}
+	if err := conn.Write(ctx, websocket.MessageText, b); err != nil {
+		s.logf("failed to send %s to the property inspector: %v", event.Event, err)
+	}
+}
@@ -0,0 +1,76 @@
+package sdtest_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/FlowingSPDG/streamdeck"
+	sdcontext "github.com/FlowingSPDG/streamdeck/context"
+	"github.com/FlowingSPDG/streamdeck/sdtest"
+)
+
+func TestPushToPropertyInspector(t *testing.T) {
+	srv := sdtest.NewServer(t)
+	client := streamdeck.NewClient(context.Background(), srv.RegistrationParams())
+	var pushes atomic.Int32
+	push := client.PushToPropertyInspector(client.Action("com.example.live"), 10*time.Millisecond, func(ctx context.Context) (any, error) {
+		return map[string]int32{"n": pushes.Add(1)}, nil
+	})
+	defer push.Stop()
+	srv.RunClient(client)
+
+	inst := sdtest.NewInstance("com.example.live", 0, 0)
+	ctx := sdcontext.WithContext(context.Background(), inst.Context)
+	srv.WillAppear(inst, nil)
+	time.Sleep(30 * time.Millisecond)
+	if client.PropertyInspectorOpen(ctx) || pushes.Load() != 0 {
+		t.Fatalf("pushed %d times before the property inspector opened", pushes.Load())
+	}
+
+	pi := srv.OpenPropertyInspector(inst, nil)
+	var first, second struct{ N int32 }
+	pi.Expect(&first)
+	pi.Expect(&second)
+	if second.N <= first.N {
+		t.Errorf("pushed %d then %d, want increasing values", first.N, second.N)
+	}
+	if !client.PropertyInspectorOpen(ctx) {
+		t.Error("PropertyInspectorOpen() = false while open")
+	}
+
+	pi.Close(nil)
+	deadline := time.Now().Add(srv.Timeout)
+	for client.PropertyInspectorOpen(ctx) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if client.PropertyInspectorOpen(ctx) {
+		t.Fatal("PropertyInspectorOpen() = true after close")
+	}
+	time.Sleep(20 * time.Millisecond)
+	n := pushes.Load()
+	time.Sleep(50 * time.Millisecond)
+	if pushes.Load() != n {
+		t.Errorf("pushed %d times after close", pushes.Load()-n)
+	}
+}
+
+func TestPushToPropertyInspectorDefaultInterval(t *testing.T) {
+	srv := sdtest.NewServer(t)
+	client := streamdeck.NewClient(context.Background(), srv.RegistrationParams())
+	push := client.PushToPropertyInspector(client.Action("com.example.live"), 0, func(ctx context.Context) (any, error) {
+		return map[string]string{"status": "ok"}, nil
+	})
+	defer push.Stop()
+	srv.RunClient(client)
+
+	inst := sdtest.NewInstance("com.example.live", 0, 0)
+	srv.WillAppear(inst, nil)
+	pi := srv.OpenPropertyInspector(inst, nil)
+	var got struct{ Status string }
+	pi.Expect(&got)
+	if got.Status != "ok" {
+		t.Errorf("pushed %+v", got)
+	}
+}
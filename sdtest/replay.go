@@ -0,0 +1,143 @@
+package sdtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/FlowingSPDG/streamdeck"
+	"golang.org/x/xerrors"
+)
+
+// Mismatch An outbound message of a replay that differs from the recording.
+type Mismatch struct {
+	// Index position among the outbound messages, registration excluded.
+	Index int
+	// Want recorded message, nil when the plugin sent more messages than recorded.
+	Want json.RawMessage
+	// Got message sent by the plugin, nil when it sent fewer messages than recorded.
+	Got json.RawMessage
+}
+
+func (m Mismatch) String() string {
+	switch {
+	case m.Want == nil:
+		return fmt.Sprintf("#%d: unexpected %s", m.Index, m.Got)
+	case m.Got == nil:
+		return fmt.Sprintf("#%d: missing %s", m.Index, m.Want)
+	default:
+		return fmt.Sprintf("#%d: got %s, want %s", m.Index, m.Got, m.Want)
+	}
+}
+
+// Replay Feed the inbound messages of a recording made with streamdeck.Recorder to the plugin, and compare its outbound messages with the recorded ones.
+// Messages are sent at the recorded pace divided by speed, e.g. 10 replays ten times faster. A speed of 0 sends them without waiting.
+// Once all messages are sent, it waits up to Timeout for the plugin to send as many messages as recorded, and returns the messages that differ, in order.
+// The registration is excluded: the plugin must be registered to the server already.
+func (s *Server) Replay(recording []streamdeck.RecordedMessage, speed float64) ([]Mismatch, error) {
+	s.mu.Lock()
+	start := len(s.messages)
+	s.mu.Unlock()
+
+	var want []json.RawMessage
+	var begin time.Time
+	replayStart := time.Now()
+	for _, m := range recording {
+		if m.Direction == streamdeck.Outbound {
+			var event streamdeck.Event
+			if err := json.Unmarshal(m.Message, &event); err != nil {
+				return nil, xerrors.Errorf("%w: %v", streamdeck.ErrInvalidMessage, err)
+			}
+			if event.Event != s.params.RegisterEvent {
+				want = append(want, m.Message)
+			}
+			continue
+		}
+
+		if begin.IsZero() {
+			begin = m.Time
+		}
+		if speed > 0 {
+			at := replayStart.Add(time.Duration(float64(m.Time.Sub(begin)) / speed))
+			time.Sleep(time.Until(at))
+		}
+		if err := s.write(m.Message); err != nil {
+			return nil, xerrors.Errorf("failed to replay message: %w", err)
+		}
+	}
+
+	deadline := time.After(s.Timeout)
+wait:
+	for {
+		s.mu.Lock()
+		n := len(s.messages) - start
+		notify := s.notify
+		s.mu.Unlock()
+		if n >= len(want) {
+			break
+		}
+		select {
+		case <-notify:
+		case <-deadline:
+			break wait
+		}
+	}
+
+	s.mu.Lock()
+	got := make([]json.RawMessage, 0, len(s.messages)-start)
+	for _, event := range s.messages[start:] {
+		b, err := json.Marshal(event)
+		if err != nil {
+			s.mu.Unlock()
+			return nil, xerrors.Errorf("%w: %v", streamdeck.ErrJSONMarshal, err)
+		}
+		got = append(got, b)
+	}
+	s.mu.Unlock()
+
+	return diffMessages(want, got), nil
+}
+
+// ExpectReplay Replay the recording and fail the test if the plugin's outbound messages differ from the recorded ones. See Replay.
+func (s *Server) ExpectReplay(recording []streamdeck.RecordedMessage, speed float64) {
+	s.helper()
+	mismatches, err := s.Replay(recording, speed)
+	if err != nil {
+		s.fatalf("%v", err)
+	}
+	if len(mismatches) == 0 {
+		return
+	}
+	for _, m := range mismatches {
+		s.logf("%s", m)
+	}
+	s.fatalf("replay differs from the recording in %d messages", len(mismatches))
+}
+
+func diffMessages(want, got []json.RawMessage) []Mismatch {
+	var mismatches []Mismatch
+	for i := 0; i < len(want) || i < len(got); i++ {
+		m := Mismatch{Index: i}
+		if i < len(want) {
+			m.Want = want[i]
+		}
+		if i < len(got) {
+			m.Got = got[i]
+		}
+		if m.Want != nil && m.Got != nil && sameJSON(m.Want, m.Got) {
+			continue
+		}
+		mismatches = append(mismatches, m)
+	}
+	return mismatches
+}
+
+// sameJSON compares JSON documents regardless of key order and formatting.
+func sameJSON(a, b json.RawMessage) bool {
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,72 @@
+package sdtest_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/FlowingSPDG/streamdeck"
+	"github.com/FlowingSPDG/streamdeck/sdtest"
+)
+
+func record(t *testing.T) []streamdeck.RecordedMessage {
+	srv := sdtest.NewServer(t)
+	client := streamdeck.NewClient(context.Background(), srv.RegistrationParams())
+	setup(client)
+	var b bytes.Buffer
+	client.Record(streamdeck.NewRecorder(&b))
+	srv.RunClient(client)
+
+	inst := sdtest.NewInstance("com.example.counter", 0, 0)
+	srv.WillAppear(inst, settings{Counter: 1})
+	srv.ExpectSetTitle(inst.Context, "1")
+	srv.KeyDown(inst, settings{Counter: 1})
+	srv.ExpectSetTitle(inst.Context, "2")
+	srv.Close()
+
+	recording, err := streamdeck.ReadRecording(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// registration, willAppear, setTitle, keyDown, setSettings, setTitle
+	if len(recording) != 6 {
+		t.Fatalf("recorded %d messages, want 6", len(recording))
+	}
+	return recording
+}
+
+func TestReplay(t *testing.T) {
+	recording := record(t)
+
+	srv := sdtest.NewServer(t)
+	client := streamdeck.NewClient(context.Background(), srv.RegistrationParams())
+	setup(client)
+	srv.RunClient(client)
+	srv.ExpectReplay(recording, 100)
+}
+
+func TestReplayMismatch(t *testing.T) {
+	recording := record(t)
+
+	srv := sdtest.NewServer(t)
+	client := streamdeck.NewClient(context.Background(), srv.RegistrationParams())
+	action := client.Action("com.example.counter")
+	streamdeck.OnWillAppear(action, func(ctx context.Context, client *streamdeck.Client, p streamdeck.WillAppearPayload[settings]) error {
+		return client.SetTitle(ctx, "changed", streamdeck.HardwareAndSoftware)
+	})
+	srv.RunClient(client)
+	srv.Timeout = 200 * time.Millisecond
+
+	mismatches, err := srv.Replay(recording, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the changed title, and the missing setSettings and setTitle of keyDown
+	if len(mismatches) != 3 {
+		t.Fatalf("got %d mismatches, want 3: %v", len(mismatches), mismatches)
+	}
+	if mismatches[0].Got == nil || mismatches[1].Got != nil {
+		t.Errorf("unexpected mismatches: %v", mismatches)
+	}
+}
@@ -0,0 +1,387 @@
+// Package sdtest provides an in-process fake Stream Deck software for testing plugins.
+package sdtest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FlowingSPDG/streamdeck"
+	"github.com/coder/websocket"
+	"golang.org/x/xerrors"
+)
+
+// DefaultTimeout Time waited for the plugin to connect or send an expected message.
+const DefaultTimeout = 2 * time.Second
+
+// Devices of the default registration info.
+const (
+	// DeviceID Stream Deck (5x3)
+	DeviceID = "SDTEST0000000000000000000000DECK"
+	// PlusDeviceID Stream Deck + (4x2)
+	PlusDeviceID = "SDTEST0000000000000000000000PLUS"
+)
+
+// RegisterPlugin event name of the plugin registration.
+const RegisterPlugin = streamdeck.RegisterPlugin
+
+// ErrNotConnected the plugin is not connected to the server.
+var ErrNotConnected = errors.New("plugin is not connected")
+
+// Server In-process fake Stream Deck software. Plugins connect to it with the RegistrationParams it provides.
+// Events are injected with Send or helpers such as KeyDown, and messages sent by the plugin are recorded for Expect assertions.
+type Server struct {
+	// Timeout time waited for the plugin to connect or send an expected message. DefaultTimeout by default.
+	Timeout time.Duration
+
+	t      testing.TB
+	srv    *httptest.Server
+	params streamdeck.RegistrationParams
+
+	mu         sync.Mutex
+	conn       *websocket.Conn
+	registered chan struct{}
+	messages   []streamdeck.Event
+	cursor     int
+	notify     chan struct{}
+	hooks      []messageHook
+	nextHook   int
+	cancel     context.CancelFunc
+	// inspectors connections of PropertyInspectorClients, by the context of their instance
+	inspectors        map[string]*websocket.Conn
+	inspectorsChanged chan struct{}
+	inspectorMessages []streamdeck.Event
+}
+
+// NewServer Start a new server closed at the end of the test. The default registration info has a Stream Deck and a Stream Deck + device.
+func NewServer(t testing.TB, info ...streamdeck.Info) *Server {
+	t.Helper()
+	s := newServer(httptest.NewServer(nil), info...)
+	s.t = t
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Start Start a new server listening on addr, for use outside of tests. Use "127.0.0.1:0" for a random port.
+func Start(addr string, info ...streamdeck.Info) (*Server, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	srv := httptest.NewUnstartedServer(nil)
+	srv.Listener.Close()
+	srv.Listener = l
+	srv.Start()
+	return newServer(srv, info...), nil
+}
+
+func newServer(srv *httptest.Server, info ...streamdeck.Info) *Server {
+	s := &Server{
+		Timeout:           DefaultTimeout,
+		srv:               srv,
+		registered:        make(chan struct{}),
+		notify:            make(chan struct{}),
+		inspectors:        map[string]*websocket.Conn{},
+		inspectorsChanged: make(chan struct{}),
+	}
+	srv.Config.Handler = http.HandlerFunc(s.handle)
+
+	i := DefaultInfo()
+	if len(info) > 0 {
+		i = info[0]
+	}
+	s.params = streamdeck.RegistrationParams{
+		Port:          srv.Listener.Addr().(*net.TCPAddr).Port,
+		PluginUUID:    randomID(),
+		RegisterEvent: RegisterPlugin,
+		Info:          i,
+	}
+	return s
+}
+
+// DefaultInfo Get the registration info used when none is specified.
+func DefaultInfo() streamdeck.Info {
+	platform := "windows"
+	if runtime.GOOS == "darwin" {
+		platform = "mac"
+	}
+	return streamdeck.Info{
+		Application: streamdeck.Application{
+			Font:     "Segoe UI",
+			Language: "en",
+			Platform: platform,
+			Version:  "6.5.0",
+		},
+		Plugin:           streamdeck.Plugin{UUID: "com.example.sdtest", Version: "0.1"},
+		DevicePixelRatio: 1,
+		Devices: []streamdeck.Device{
+			{ID: DeviceID, Name: "Stream Deck", Size: streamdeck.Size{Columns: 5, Rows: 3}, Type: int(streamdeck.StreamDeck)},
+			{ID: PlusDeviceID, Name: "Stream Deck +", Size: streamdeck.Size{Columns: 4, Rows: 2}, Type: int(streamdeck.StreamDeckPlus)},
+		},
+	}
+}
+
+// RegistrationParams Get the params a plugin connects to the server with.
+func (s *Server) RegistrationParams() streamdeck.RegistrationParams {
+	return s.params
+}
+
+// Args Get the command line arguments a plugin binary connects to the server with, without the program name.
+func (s *Server) Args() []string {
+	info, _ := json.Marshal(s.params.Info)
+	return []string{
+		"-port", strconv.Itoa(s.params.Port),
+		"-pluginUUID", s.params.PluginUUID,
+		"-registerEvent", s.params.RegisterEvent,
+		"-info", string(info),
+	}
+}
+
+// RunClient Run the client against the server in the background, and wait for its registration.
+// The client is stopped when the server is closed.
+func (s *Server) RunClient(client *streamdeck.Client) {
+	s.helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	go func() {
+		if err := client.Run(ctx); err != nil && ctx.Err() == nil {
+			s.logf("client stopped: %v", err)
+		}
+	}()
+	if err := s.WaitRegistered(); err != nil {
+		s.fatalf("%v", err)
+	}
+}
+
+// WaitRegistered Wait for a plugin to connect and register.
+func (s *Server) WaitRegistered() error {
+	s.mu.Lock()
+	registered := s.registered
+	s.mu.Unlock()
+
+	select {
+	case <-registered:
+		return nil
+	case <-time.After(s.Timeout):
+		return xerrors.Errorf("plugin did not register within %v: %w", s.Timeout, ErrNotConnected)
+	}
+}
+
+// Connected Check if a plugin is connected and registered.
+func (s *Server) Connected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn != nil
+}
+
+type messageHook struct {
+	id int
+	fn func(streamdeck.Event)
+}
+
+// OnMessage Register a hook called for every message sent by the plugin. remove unregisters it.
+func (s *Server) OnMessage(hook func(event streamdeck.Event)) (remove func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.nextHook
+	s.nextHook++
+	s.hooks = append(s.hooks, messageHook{id: id, fn: hook})
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		// copy, handle iterates over the previous slice without the lock
+		s.hooks = slices.DeleteFunc(slices.Clone(s.hooks), func(h messageHook) bool {
+			return h.id == id
+		})
+	}
+}
+
+// Send Send an event to the plugin. It waits for the plugin to register first.
+func (s *Server) Send(event streamdeck.Event) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return xerrors.Errorf("%w: %v", streamdeck.ErrJSONMarshal, err)
+	}
+	return s.write(b)
+}
+
+func (s *Server) write(b []byte) error {
+	if err := s.WaitRegistered(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	conn := s.conn
+	s.mu.Unlock()
+	if conn == nil {
+		return ErrNotConnected
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.Timeout)
+	defer cancel()
+	if err := conn.Write(ctx, websocket.MessageText, b); err != nil {
+		return xerrors.Errorf("%w: %v", streamdeck.ErrWriteFailed, err)
+	}
+	return nil
+}
+
+// Messages Get all messages sent by the plugin, registration excluded. Payloads are json.RawMessage.
+func (s *Server) Messages() []streamdeck.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]streamdeck.Event{}, s.messages...)
+}
+
+// Close Stop the client started by RunClient and the server.
+func (s *Server) Close() {
+	s.mu.Lock()
+	cancel := s.cancel
+	conn := s.conn
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	if conn != nil {
+		conn.Close(websocket.StatusGoingAway, "")
+	}
+	s.srv.Close()
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		s.logf("failed to accept websocket: %v", err)
+		return
+	}
+	conn.SetReadLimit(-1)
+
+	ctx := r.Context()
+	registered := false
+	// inspector context of the instance when the connection is a property inspector
+	inspector := ""
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.conn == conn {
+			s.conn = nil
+			s.registered = make(chan struct{})
+		}
+		if inspector != "" && s.inspectors[inspector] == conn {
+			delete(s.inspectors, inspector)
+		}
+	}()
+
+	for {
+		_, b, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+
+		var raw struct {
+			streamdeck.Event
+			Payload json.RawMessage `json:"payload,omitempty"`
+		}
+		if err := json.Unmarshal(b, &raw); err != nil {
+			s.logf("failed to unmarshal message: %s", b)
+			continue
+		}
+		event := raw.Event
+		event.Payload = raw.Payload
+		if raw.Payload == nil {
+			event.Payload = nil
+		}
+
+		if !registered {
+			switch {
+			case event.Event == streamdeck.RegisterPropertyInspector && event.UUID != "":
+				inspector = event.UUID
+				s.mu.Lock()
+				s.inspectors[inspector] = conn
+				close(s.inspectorsChanged)
+				s.inspectorsChanged = make(chan struct{})
+				s.mu.Unlock()
+			case event.Event == s.params.RegisterEvent && event.UUID == s.params.PluginUUID:
+				s.mu.Lock()
+				s.conn = conn
+				close(s.registered)
+				s.mu.Unlock()
+			default:
+				s.logf("unexpected registration: %s", b)
+				conn.Close(websocket.StatusPolicyViolation, "invalid registration")
+				return
+			}
+			registered = true
+			continue
+		}
+
+		if inspector != "" {
+			s.fromInspector(event)
+			continue
+		}
+
+		// hooks run before the message is recorded, so state they keep is up to date once it is expected
+		s.mu.Lock()
+		hooks := s.hooks
+		s.mu.Unlock()
+		for _, hook := range hooks {
+			hook.fn(event)
+		}
+
+		s.record(event)
+		if event.Event == streamdeck.SendToPropertyInspector {
+			s.toInspector(ctx, event)
+		}
+	}
+}
+
+func (s *Server) record(event streamdeck.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, event)
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
+
+func (s *Server) helper() {
+	if s.t != nil {
+		s.t.Helper()
+	}
+}
+
+func (s *Server) logf(format string, args ...any) {
+	if s.t != nil {
+		s.t.Logf(format, args...)
+		return
+	}
+	streamdeck.Log().Printf(format+"\n", args...)
+}
+
+func (s *Server) fatalf(format string, args ...any) {
+	if s.t != nil {
+		s.t.Helper()
+		s.t.Fatalf(format, args...)
+		return
+	}
+	panic(xerrors.Errorf(format, args...))
+}
+
+func randomID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
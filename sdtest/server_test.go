@@ -0,0 +1,75 @@
+package sdtest_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/FlowingSPDG/streamdeck"
+	"github.com/FlowingSPDG/streamdeck/sdtest"
+)
+
+type settings struct {
+	Counter int `json:"counter"`
+}
+
+func setup(client *streamdeck.Client) {
+	action := client.Action("com.example.counter")
+
+	streamdeck.OnWillAppear(action, func(ctx context.Context, client *streamdeck.Client, p streamdeck.WillAppearPayload[settings]) error {
+		return client.SetTitle(ctx, strconv.Itoa(p.Settings.Counter), streamdeck.HardwareAndSoftware)
+	})
+
+	streamdeck.OnKeyDown(action, func(ctx context.Context, client *streamdeck.Client, p streamdeck.KeyDownPayload[settings]) error {
+		p.Settings.Counter++
+		if err := client.SetSettings(ctx, p.Settings); err != nil {
+			return err
+		}
+		return client.SetTitle(ctx, strconv.Itoa(p.Settings.Counter), streamdeck.HardwareAndSoftware)
+	})
+}
+
+func TestServer(t *testing.T) {
+	srv := sdtest.NewServer(t)
+	client := streamdeck.NewClient(context.Background(), srv.RegistrationParams())
+	setup(client)
+	srv.RunClient(client)
+
+	inst := sdtest.NewInstance("com.example.counter", 0, 0)
+	srv.WillAppear(inst, settings{Counter: 2})
+	srv.ExpectSetTitle(inst.Context, "2")
+
+	srv.KeyDown(inst, settings{Counter: 2})
+	var s settings
+	srv.ExpectSetSettings(inst.Context, &s)
+	if s.Counter != 3 {
+		t.Errorf("Counter = %d, want 3", s.Counter)
+	}
+	srv.ExpectSetTitle(inst.Context, "3")
+
+	other := sdtest.NewInstance("com.example.counter", 1, 0)
+	srv.KeyDown(other, settings{Counter: 9})
+	srv.ExpectSetTitle(other.Context, "10")
+	srv.ExpectNone(streamdeck.SetTitle, inst.Context, 0)
+}
+
+func TestOnMessageRemove(t *testing.T) {
+	srv := sdtest.NewServer(t)
+	client := streamdeck.NewClient(context.Background(), srv.RegistrationParams())
+	setup(client)
+	srv.RunClient(client)
+
+	calls := 0
+	remove := srv.OnMessage(func(event streamdeck.Event) {
+		calls++
+	})
+	inst := sdtest.NewInstance("com.example.counter", 0, 0)
+	srv.WillAppear(inst, settings{Counter: 1})
+	srv.ExpectSetTitle(inst.Context, "1")
+	remove()
+	srv.KeyDown(inst, settings{Counter: 1})
+	srv.ExpectSetTitle(inst.Context, "2")
+	if calls != 1 {
+		t.Errorf("hook called %d times, want 1 before it was removed", calls)
+	}
+}
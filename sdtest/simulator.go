@@ -0,0 +1,190 @@
+package sdtest
+
+import (
+	"encoding/json"
+	"image"
+	"testing"
+
+	"github.com/FlowingSPDG/streamdeck"
+)
+
+// Simulator A Server that behaves like the Stream Deck software: it keeps per-instance and global settings,
+// answers getSettings and getGlobalSettings, and tracks the title, image, state and feedback of every instance on a virtual Deck.
+type Simulator struct {
+	*Server
+	deck *Deck
+}
+
+// NewSimulator Start a new simulator closed at the end of the test. Devices of the deck are laid out from info.Devices.
+func NewSimulator(t testing.TB, info ...streamdeck.Info) *Simulator {
+	t.Helper()
+	return newSimulator(NewServer(t, info...))
+}
+
+// StartSimulator Start a new simulator listening on addr, for use outside of tests.
+func StartSimulator(addr string, info ...streamdeck.Info) (*Simulator, error) {
+	s, err := Start(addr, info...)
+	if err != nil {
+		return nil, err
+	}
+	return newSimulator(s), nil
+}
+
+func newSimulator(s *Server) *Simulator {
+	sim := &Simulator{
+		Server: s,
+		deck:   NewDeck(s.params.Info),
+	}
+	s.OnMessage(func(event streamdeck.Event) {
+		reply, err := sim.deck.Apply(event)
+		if err != nil {
+			s.logf("failed to apply %s: %v", event.Event, err)
+			return
+		}
+		if reply == nil {
+			return
+		}
+		// reply from another goroutine, the plugin may be waiting for its own write to complete
+		go func() {
+			if err := s.Send(*reply); err != nil {
+				s.logf("failed to reply %s: %v", reply.Event, err)
+			}
+		}()
+	})
+	return sim
+}
+
+// Deck Get the virtual deck.
+func (sim *Simulator) Deck() *Deck {
+	return sim.deck
+}
+
+// Snapshot Render the keypad of the device. See Deck.Snapshot.
+func (sim *Simulator) Snapshot(device string) image.Image {
+	sim.helper()
+	img, err := sim.deck.Snapshot(device)
+	if err != nil {
+		sim.fatalf("%v", err)
+	}
+	return img
+}
+
+// Place Add an instance of the action to a key of the device, and send willAppear with its settings.
+func (sim *Simulator) Place(action, device string, column, row int, settings any) Instance {
+	sim.helper()
+	return sim.place(action, device, Keypad, column, row, settings)
+}
+
+// PlaceDial Add an instance of the action to a dial of the Stream Deck + device, and send willAppear with its settings.
+func (sim *Simulator) PlaceDial(action, device string, index int, settings any) Instance {
+	sim.helper()
+	return sim.place(action, device, Encoder, index, 0, settings)
+}
+
+func (sim *Simulator) place(action, device, controller string, column, row int, settings any) Instance {
+	sim.helper()
+	k, err := sim.deck.Place(action, device, controller, column, row, settings)
+	if err != nil {
+		sim.fatalf("failed to place %s: %v", action, err)
+	}
+	sim.Appear(k.Instance)
+	return k.Instance
+}
+
+// Appear Send willAppear for an instance already on the deck, e.g. after the plugin restarted.
+func (sim *Simulator) Appear(inst Instance) {
+	sim.helper()
+	k := sim.key(inst)
+	sim.WillAppear(k.Instance, k.Settings)
+}
+
+// Remove Send willDisappear for the instance and remove it from the deck.
+func (sim *Simulator) Remove(inst Instance) {
+	sim.helper()
+	k := sim.key(inst)
+	sim.deck.Remove(k.Context)
+	sim.WillDisappear(k.Instance, k.Settings)
+}
+
+// SetStates Set the number of states of the instance, as declared in the manifest. The state advances on keyUp when it is more than 1.
+func (sim *Simulator) SetStates(inst Instance, states int) {
+	sim.helper()
+	sim.key(inst)
+	sim.deck.Update(inst.Context, func(k *Key) {
+		k.States = states
+	})
+}
+
+// Press Press and release the key with its current settings and state.
+func (sim *Simulator) Press(inst Instance) {
+	sim.helper()
+	sim.KeyDown(sim.key(inst).Instance, sim.key(inst).Settings)
+	sim.Release(inst)
+}
+
+// Release Release the key. The state of multi-state actions advances like the Stream Deck software does.
+func (sim *Simulator) Release(inst Instance) {
+	sim.helper()
+	k := sim.key(inst)
+	sim.deck.AdvanceState(k.Context)
+	sim.KeyUp(k.Instance, k.Settings)
+}
+
+// Rotate Rotate the dial with its current settings.
+func (sim *Simulator) Rotate(inst Instance, ticks int, pressed bool) {
+	sim.helper()
+	k := sim.key(inst)
+	sim.DialRotate(k.Instance, k.Settings, ticks, pressed)
+}
+
+// PushDial Press and release the dial with its current settings.
+func (sim *Simulator) PushDial(inst Instance) {
+	sim.helper()
+	k := sim.key(inst)
+	sim.DialDown(k.Instance, k.Settings)
+	sim.DialUp(k.Instance, k.Settings)
+}
+
+// Touch Tap the touch display segment of the dial.
+func (sim *Simulator) Touch(inst Instance, pos [2]int, hold bool) {
+	sim.helper()
+	k := sim.key(inst)
+	sim.TouchTap(k.Instance, k.Settings, pos, hold)
+}
+
+// ChangeSettings Change the settings of the instance, as the property inspector would, and send didReceiveSettings.
+func (sim *Simulator) ChangeSettings(inst Instance, settings any) {
+	sim.helper()
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		sim.fatalf("failed to marshal settings: %v", err)
+	}
+	sim.key(inst)
+	sim.deck.Update(inst.Context, func(k *Key) {
+		k.Settings = raw
+	})
+	sim.DidReceiveSettings(sim.key(inst).Instance, json.RawMessage(raw))
+}
+
+// Settings Unmarshal the settings of the instance into v.
+func (sim *Simulator) Settings(inst Instance, v any) {
+	sim.helper()
+	if err := json.Unmarshal(sim.key(inst).Settings, v); err != nil {
+		sim.fatalf("failed to unmarshal settings: %v", err)
+	}
+}
+
+// Key Get the state of the instance on the deck.
+func (sim *Simulator) Key(inst Instance) Key {
+	sim.helper()
+	return sim.key(inst)
+}
+
+func (sim *Simulator) key(inst Instance) Key {
+	sim.helper()
+	k, ok := sim.deck.Key(inst.Context)
+	if !ok {
+		sim.fatalf("instance %s of %s is not on the deck", inst.Context, inst.Action)
+	}
+	return k
+}
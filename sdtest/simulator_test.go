@@ -0,0 +1,117 @@
+package sdtest_test
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"strconv"
+	"testing"
+
+	"github.com/FlowingSPDG/streamdeck"
+	"github.com/FlowingSPDG/streamdeck/sdtest"
+)
+
+func setupSimulated(client *streamdeck.Client) {
+	action := client.Action("com.example.simulated")
+
+	streamdeck.OnWillAppear(action, func(ctx context.Context, client *streamdeck.Client, p streamdeck.WillAppearPayload[settings]) error {
+		img := image.NewRGBA(image.Rect(0, 0, 72, 72))
+		for x := 0; x < 72; x++ {
+			for y := 0; y < 72; y++ {
+				img.Set(x, y, color.RGBA{R: uint8(p.Settings.Counter * 50), A: 255})
+			}
+		}
+		s, err := streamdeck.Image(img)
+		if err != nil {
+			return err
+		}
+		if err := client.SetImage(ctx, s, streamdeck.HardwareAndSoftware); err != nil {
+			return err
+		}
+		return client.GetSettings(ctx)
+	})
+
+	streamdeck.OnDidReceiveSettings(action, func(ctx context.Context, client *streamdeck.Client, p streamdeck.DidReceiveSettingsPayload[settings]) error {
+		return client.SetTitle(ctx, "settings "+strconv.Itoa(p.Settings.Counter), streamdeck.HardwareAndSoftware)
+	})
+
+	streamdeck.OnKeyDown(action, func(ctx context.Context, client *streamdeck.Client, p streamdeck.KeyDownPayload[settings]) error {
+		p.Settings.Counter++
+		if err := client.SetSettings(ctx, p.Settings); err != nil {
+			return err
+		}
+		if err := client.SetState(ctx, p.Settings.Counter%2); err != nil {
+			return err
+		}
+		return client.SetTitle(ctx, strconv.Itoa(p.Settings.Counter), streamdeck.HardwareAndSoftware)
+	})
+}
+
+func TestSimulator(t *testing.T) {
+	sim := sdtest.NewSimulator(t)
+	client := streamdeck.NewClient(context.Background(), sim.RegistrationParams())
+	setupSimulated(client)
+	sim.RunClient(client)
+
+	inst := sim.Place("com.example.simulated", sdtest.DeviceID, 1, 0, settings{Counter: 1})
+	sim.ExpectSetImage(inst.Context)
+	sim.ExpectSetTitle(inst.Context, "settings 1")
+
+	// settings persisted by the plugin are sent back on the next press
+	sim.Press(inst)
+	sim.ExpectSetTitle(inst.Context, "2")
+	sim.Press(inst)
+	sim.ExpectSetTitle(inst.Context, "3")
+
+	var s settings
+	sim.Settings(inst, &s)
+	if s.Counter != 3 {
+		t.Errorf("Counter = %d, want 3", s.Counter)
+	}
+	if k := sim.Key(inst); k.Title != "3" || k.State != 1 {
+		t.Errorf("key title = %q state = %d, want 3 and 1", k.Title, k.State)
+	}
+
+	sim.ChangeSettings(inst, settings{Counter: 7})
+	sim.ExpectSetTitle(inst.Context, "settings 7")
+
+	sim.Place("com.example.simulated", sdtest.DeviceID, 4, 2, settings{Counter: 4})
+	sim.ExpectSetTitle("", "settings 4")
+	sdtest.MatchGolden(t, "testdata/simulator.png", sim.Snapshot(sdtest.DeviceID))
+}
+
+func TestSimulatorPlaceOutsideDevice(t *testing.T) {
+	deck := sdtest.NewDeck(sdtest.DefaultInfo())
+	if _, err := deck.Place("com.example.simulated", sdtest.DeviceID, sdtest.Keypad, 5, 0, nil); err == nil {
+		t.Error("Place() outside of the device should fail")
+	}
+	if _, err := deck.Place("com.example.simulated", sdtest.PlusDeviceID, sdtest.Encoder, 3, 0, nil); err != nil {
+		t.Errorf("Place() error = %v", err)
+	}
+}
+
+func TestDeckAdvanceState(t *testing.T) {
+	deck := sdtest.NewDeck(sdtest.DefaultInfo())
+	k, err := deck.Place("com.example.simulated", sdtest.DeviceID, sdtest.Keypad, 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := deck.AdvanceState(k.Context); ok {
+		t.Error("AdvanceState() advanced a single-state key")
+	}
+
+	deck.Update(k.Context, func(k *sdtest.Key) { k.States = 2 })
+	for _, want := range []int{1, 0} {
+		if state, ok := deck.AdvanceState(k.Context); !ok || state != want {
+			t.Errorf("AdvanceState() = %d, %v, want %d", state, ok, want)
+		}
+	}
+
+	deck.Update(k.Context, func(k *sdtest.Key) { k.IsInMultiAction = true })
+	if _, ok := deck.AdvanceState(k.Context); ok {
+		t.Error("AdvanceState() advanced a key in a multi-action")
+	}
+	if _, ok := deck.AdvanceState("unknown"); ok {
+		t.Error("AdvanceState() advanced an unknown key")
+	}
+}
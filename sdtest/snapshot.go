@@ -0,0 +1,144 @@
+package sdtest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg" // register JPEG decoder for key images
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/xerrors"
+)
+
+// Snapshot layout of a device, in pixels.
+const (
+	SnapshotKeySize = 72
+	SnapshotGap     = 8
+)
+
+// UpdateGoldenEnv environment variable that makes MatchGolden write golden files instead of comparing them.
+const UpdateGoldenEnv = "SDTEST_UPDATE_GOLDEN"
+
+var (
+	snapshotBackground = color.RGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xff}
+	snapshotEmptyKey   = color.RGBA{R: 0x40, G: 0x40, B: 0x40, A: 0xff}
+	snapshotUnknown    = color.RGBA{R: 0xff, G: 0x00, B: 0xff, A: 0xff}
+)
+
+// Snapshot Render the keypad of the device: the latest image of each key in its grid position.
+// Empty keys are grey, keys without an image black, and images that can't be decoded (e.g. SVG) magenta. Titles are not rendered.
+func (d *Deck) Snapshot(device string) (image.Image, error) {
+	dev, ok := d.Device(device)
+	if !ok {
+		return nil, xerrors.Errorf("unknown device %q", device)
+	}
+
+	w := dev.Columns*(SnapshotKeySize+SnapshotGap) + SnapshotGap
+	h := dev.Rows*(SnapshotKeySize+SnapshotGap) + SnapshotGap
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(snapshotBackground), image.Point{}, draw.Src)
+
+	for row := 0; row < dev.Rows; row++ {
+		for col := 0; col < dev.Columns; col++ {
+			x := SnapshotGap + col*(SnapshotKeySize+SnapshotGap)
+			y := SnapshotGap + row*(SnapshotKeySize+SnapshotGap)
+			rect := image.Rect(x, y, x+SnapshotKeySize, y+SnapshotKeySize)
+
+			k, ok := d.KeyAt(device, Keypad, col, row)
+			if !ok {
+				draw.Draw(img, rect, image.NewUniform(snapshotEmptyKey), image.Point{}, draw.Src)
+				continue
+			}
+			draw.Draw(img, rect, image.NewUniform(color.Black), image.Point{}, draw.Src)
+			if k.Image == "" {
+				continue
+			}
+			keyImg, err := decodeDataURI(k.Image)
+			if err != nil {
+				draw.Draw(img, rect, image.NewUniform(snapshotUnknown), image.Point{}, draw.Src)
+				continue
+			}
+			drawScaled(img, rect, keyImg)
+		}
+	}
+	return img, nil
+}
+
+// MatchGolden Compare img with the PNG golden file at path, failing the test when they differ.
+// The golden file is written instead when it doesn't exist or the SDTEST_UPDATE_GOLDEN environment variable is set.
+func MatchGolden(t testing.TB, path string, img image.Image) {
+	t.Helper()
+
+	if _, err := os.Stat(path); os.Getenv(UpdateGoldenEnv) != "" || os.IsNotExist(err) {
+		var b bytes.Buffer
+		if err := png.Encode(&b, img); err != nil {
+			t.Fatalf("failed to encode golden image: %v", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+			t.Fatalf("failed to write golden image: %v", err)
+		}
+		t.Logf("wrote golden image %s", path)
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open golden image: %v", err)
+	}
+	defer f.Close()
+	want, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("failed to decode golden image: %v", err)
+	}
+
+	if want.Bounds() != img.Bounds() {
+		t.Fatalf("image size %v differs from golden image %s %v", img.Bounds(), path, want.Bounds())
+	}
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if !sameColor(img.At(x, y), want.At(x, y)) {
+				t.Fatalf("image differs from golden image %s at %d,%d. set %s=1 to update", path, x, y, UpdateGoldenEnv)
+			}
+		}
+	}
+}
+
+func decodeDataURI(s string) (image.Image, error) {
+	i := strings.Index(s, ";base64,")
+	if !strings.HasPrefix(s, "data:") || i < 0 {
+		return nil, xerrors.New("not a base64 data URI")
+	}
+	b, err := base64.StdEncoding.DecodeString(s[i+len(";base64,"):])
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(b))
+	return img, err
+}
+
+// drawScaled draws src into rect of dst using nearest neighbour sampling.
+func drawScaled(dst draw.Image, rect image.Rectangle, src image.Image) {
+	sb := src.Bounds()
+	for y := 0; y < rect.Dy(); y++ {
+		for x := 0; x < rect.Dx(); x++ {
+			c := src.At(sb.Min.X+x*sb.Dx()/rect.Dx(), sb.Min.Y+y*sb.Dy()/rect.Dy())
+			dst.Set(rect.Min.X+x, rect.Min.Y+y, c)
+		}
+	}
+}
+
+func sameColor(a, b color.Color) bool {
+	r1, g1, b1, a1 := a.RGBA()
+	r2, g2, b2, a2 := b.RGBA()
+	return r1 == r2 && g1 == g2 && b1 == b2 && a1 == a2
+}